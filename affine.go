@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Afinní transformace ve 2D jako hodnota (bez alokací)
+// Odpovídá homogenní matici 3x3:
+//
+//	| A C E |
+//	| B D F |
+//	| 0 0 1 |
+type Affine2D struct {
+	A, B, C, D, E, F float64
+}
+
+func IdentityAffine() Affine2D {
+	return Affine2D{A: 1, D: 1}
+}
+
+func TranslationAffine(tX, tY float64) Affine2D {
+	return Affine2D{A: 1, D: 1, E: tX, F: tY}
+}
+
+func RotationAffine(theta float64) Affine2D {
+	cos, sin := math.Cos(theta), math.Sin(theta)
+	return Affine2D{A: cos, B: sin, C: -sin, D: cos}
+}
+
+func ScalingAffine(sX, sY float64) Affine2D {
+	return Affine2D{A: sX, D: sY}
+}
+
+// Zkosení: kx posouvá X úměrně Y, ky posouvá Y úměrně X
+func SkewAffine(kx, ky float64) Affine2D {
+	return Affine2D{A: 1, B: ky, C: kx, D: 1}
+}
+
+// Otočení kolem bodu p
+func RotationAroundAffine(p Point, theta float64) Affine2D {
+	return TranslationAffine(p.X, p.Y).Multiply(RotationAffine(theta)).Multiply(TranslationAffine(-p.X, -p.Y))
+}
+
+// Složení transformace z posunu, otočení, zkosení podle X a měřítka (v tomto pořadí zvenku dovnitř)
+// Je to opak Decompose
+func ComposeAffine(translation Vector, rotation float64, scale Vector, skew float64) Affine2D {
+	return TranslationAffine(translation.X, translation.Y).
+		Multiply(RotationAffine(rotation)).
+		Multiply(SkewAffine(skew, 0)).
+		Multiply(ScalingAffine(scale.X, scale.Y))
+}
+
+// Převod z Matrix 3x3
+func AffineFromMatrix(m *Matrix) (Affine2D, error) {
+	if m == nil || m.Rows != 3 || m.Columns != 3 {
+		return Affine2D{}, fmt.Errorf("affine transform needs a 3x3 matrix")
+	}
+	return Affine2D{
+		A: m.Data[0][0], C: m.Data[0][1], E: m.Data[0][2],
+		B: m.Data[1][0], D: m.Data[1][1], F: m.Data[1][2],
+	}, nil
+}
+
+// Převod na Matrix 3x3
+func (m Affine2D) Matrix() *Matrix {
+	result := IdentityMatrix(3)
+	result.Data[0][0], result.Data[0][1], result.Data[0][2] = m.A, m.C, m.E
+	result.Data[1][0], result.Data[1][1], result.Data[1][2] = m.B, m.D, m.F
+	return result
+}
+
+// Složení transformací m * n: na bod se nejdřív použije n, potom m
+func (m Affine2D) Multiply(n Affine2D) Affine2D {
+	return Affine2D{
+		A: m.A*n.A + m.C*n.B,
+		B: m.B*n.A + m.D*n.B,
+		C: m.A*n.C + m.C*n.D,
+		D: m.B*n.C + m.D*n.D,
+		E: m.A*n.E + m.C*n.F + m.E,
+		F: m.B*n.E + m.D*n.F + m.F,
+	}
+}
+
+// Posun, otočení a měřítko v lokálních souřadnicích transformace (jako u canvasu)
+func (m Affine2D) Translate(tX, tY float64) Affine2D {
+	return m.Multiply(TranslationAffine(tX, tY))
+}
+
+func (m Affine2D) Rotate(theta float64) Affine2D {
+	return m.Multiply(RotationAffine(theta))
+}
+
+func (m Affine2D) Scale(sX, sY float64) Affine2D {
+	return m.Multiply(ScalingAffine(sX, sY))
+}
+
+func (m Affine2D) Determinant() float64 {
+	return m.A*m.D - m.B*m.C
+}
+
+func (m Affine2D) IsIdentity() bool {
+	return m == IdentityAffine()
+}
+
+// Relativní tolerance, pod kterou se transformace považuje za degenerovanou (vůči lineární části)
+const affineEpsilon = 1e-12
+
+// Inverzní transformace
+// Determinant se porovnává s velikostí lineární části, takže i hodně zmenšený sprite zůstane invertovatelný
+func (m Affine2D) Inverse() (Affine2D, error) {
+	det := m.Determinant()
+	lin := math.Max(math.Max(math.Abs(m.A), math.Abs(m.B)), math.Max(math.Abs(m.C), math.Abs(m.D)))
+	if lin == 0 || math.Abs(det) <= affineEpsilon*lin*lin {
+		return Affine2D{}, fmt.Errorf("affine transform is not invertible")
+	}
+	inv := 1 / det
+	return Affine2D{
+		A: m.D * inv,
+		B: -m.B * inv,
+		C: -m.C * inv,
+		D: m.A * inv,
+		E: (m.C*m.F - m.D*m.E) * inv,
+		F: (m.B*m.E - m.A*m.F) * inv,
+	}, nil
+}
+
+func (m Affine2D) TransformPoint(p Point) Point {
+	return Point{X: m.A*p.X + m.C*p.Y + m.E, Y: m.B*p.X + m.D*p.Y + m.F}
+}
+
+// Transformace vektoru (směru) - posun se neuplatní
+func (m Affine2D) TransformVector(v Vector) Vector {
+	return Vector{X: m.A*v.X + m.C*v.Y, Y: m.B*v.X + m.D*v.Y}
+}
+
+// Osově zarovnaný obdélník, do kterého se vejde transformovaný obdélník
+func (m Affine2D) TransformRect(r Rectangle) Rectangle {
+	c := m.TransformPoint(r.Min)
+	ex := m.TransformVector(Vector{X: r.Max.X - r.Min.X})
+	ey := m.TransformVector(Vector{Y: r.Max.Y - r.Min.Y})
+	return Rectangle{
+		Min: Point{X: c.X + math.Min(ex.X, 0) + math.Min(ey.X, 0), Y: c.Y + math.Min(ex.Y, 0) + math.Min(ey.Y, 0)},
+		Max: Point{X: c.X + math.Max(ex.X, 0) + math.Max(ey.X, 0), Y: c.Y + math.Max(ex.Y, 0) + math.Max(ey.Y, 0)},
+	}
+}
+
+// Rozklad na posun, otočení (rad), měřítko a zkosení podle X tak, aby
+// ComposeAffine(Decompose()) vrátilo původní transformaci; zrcadlení se projeví záporným scale.Y
+func (m Affine2D) Decompose() (translation Vector, rotation float64, scale Vector, skew float64) {
+	translation = Vector{X: m.E, Y: m.F}
+	scale.X = math.Hypot(m.A, m.B)
+	if scale.X < collisionEpsilon {
+		return translation, 0, Vector{Y: math.Hypot(m.C, m.D)}, 0
+	}
+	rotation = math.Atan2(m.B, m.A)
+	cos, sin := m.A/scale.X, m.B/scale.X
+	scale.Y = cos*m.D - sin*m.C
+	if math.Abs(scale.Y) > collisionEpsilon {
+		skew = (cos*m.C + sin*m.D) / scale.Y
+	}
+	return translation, rotation, scale, skew
+}
+
+// Rozložená afinní transformace (viz Affine2D.Decompose), vhodná pro animaci
+type TransformParts struct {
+	Translation Vector
+	Rotation    float64 // v radiánech
+	Scale       Vector
+	Skew        float64
+}
+
+func (m Affine2D) Parts() TransformParts {
+	t, r, s, k := m.Decompose()
+	return TransformParts{Translation: t, Rotation: r, Scale: s, Skew: k}
+}
+
+func (p TransformParts) Affine() Affine2D {
+	return ComposeAffine(p.Translation, p.Rotation, p.Scale, p.Skew)
+}
+
+// Lineární interpolace složek; otočení jde kratším obloukem
+func LerpTransformParts(a, b TransformParts, t float64) TransformParts {
+	return TransformParts{
+		Translation: lerpVector(a.Translation, b.Translation, t),
+		Rotation:    LerpAngle(a.Rotation, b.Rotation, t),
+		Scale:       lerpVector(a.Scale, b.Scale, t),
+		Skew:        lerp(a.Skew, b.Skew, t),
+	}
+}
+
+// Plynulý přechod mezi dvěma transformacemi (t = 0 vrátí a, t = 1 vrátí b)
+// Na rozdíl od prosté interpolace prvků matice se sprite po cestě nezdeformuje
+// Mezi zrcadlenou a nezrcadlenou transformací (např. otočení postavy doleva/doprava) plynulý
+// přechod neexistuje - vedl by přes matici s nulovým determinantem - takže se pro t > 0 skočí rovnou na b
+func InterpolateAffine(a, b Affine2D, t float64) Affine2D {
+	if a == b {
+		return a
+	}
+	if orientationFlips(a, b) {
+		if t <= 0 {
+			return a
+		}
+		return b
+	}
+	return LerpTransformParts(a.Parts(), b.Parts(), t).Affine()
+}
+
+// Mění se mezi a a b orientace (znaménko determinantu), nebo je některá z nich degenerovaná?
+func orientationFlips(a, b Affine2D) bool {
+	return a.Determinant()*b.Determinant() <= 0
+}
+
+// Interpolace úhlu kratším obloukem
+func LerpAngle(a, b, t float64) float64 {
+	return a + math.Remainder(b-a, 2*math.Pi)*t
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+func lerpVector(a, b Vector, t float64) Vector {
+	return Vector{X: lerp(a.X, b.X, t), Y: lerp(a.Y, b.Y, t)}
+}
+
+func lerpPoint(a, b Point, t float64) Point {
+	return Point{X: lerp(a.X, b.X, t), Y: lerp(a.Y, b.Y, t)}
+}
+
+func (m Affine2D) String() string {
+	return fmt.Sprintf("[%g %g %g; %g %g %g]", m.A, m.C, m.E, m.B, m.D, m.F)
+}
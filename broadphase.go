@@ -0,0 +1,198 @@
+package main
+
+import "math"
+
+// Výchozí velikost buňky prostorového hashe
+const DefaultCellSize = 64
+
+type cellKey struct {
+	X, Y int
+}
+
+type hashEntry struct {
+	bounds         Rectangle
+	x0, y0, x1, y1 int // rozsah buněk, do kterých sprite zasahuje
+	stamp          uint64
+}
+
+// Prostorový hash pro rychlé hledání kolizních párů a dotazy na oblast
+// Každý sprite je zapsaný ve všech buňkách, do kterých zasahuje jeho bounding box
+type SpatialHash struct {
+	CellSize float64
+	cells    map[cellKey][]Spriter
+	entries  map[Spriter]*hashEntry
+	stamp    uint64 // značka pro odstranění duplicit při dotazech
+}
+
+func NewSpatialHash(cellSize float64) *SpatialHash {
+	if cellSize <= 0 {
+		cellSize = DefaultCellSize
+	}
+	return &SpatialHash{
+		CellSize: cellSize,
+		cells:    make(map[cellKey][]Spriter),
+		entries:  make(map[Spriter]*hashEntry),
+	}
+}
+
+func (h *SpatialHash) cellRange(r Rectangle) (x0, y0, x1, y1 int) {
+	return int(math.Floor(r.Min.X / h.CellSize)), int(math.Floor(r.Min.Y / h.CellSize)),
+		int(math.Floor(r.Max.X / h.CellSize)), int(math.Floor(r.Max.Y / h.CellSize))
+}
+
+func (h *SpatialHash) Len() int {
+	return len(h.entries)
+}
+
+// Funkce pro vložení nebo aktualizaci polohy spritu
+func (h *SpatialHash) Update(sp Spriter, bounds Rectangle) {
+	x0, y0, x1, y1 := h.cellRange(bounds)
+
+	e, exists := h.entries[sp]
+	if exists {
+		e.bounds = bounds
+		if e.x0 == x0 && e.y0 == y0 && e.x1 == x1 && e.y1 == y1 {
+			return // sprite zůstal ve stejných buňkách
+		}
+		h.unlink(sp, e)
+	} else {
+		e = &hashEntry{bounds: bounds}
+		h.entries[sp] = e
+	}
+
+	e.x0, e.y0, e.x1, e.y1 = x0, y0, x1, y1
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			k := cellKey{x, y}
+			h.cells[k] = append(h.cells[k], sp)
+		}
+	}
+}
+
+func (h *SpatialHash) Remove(sp Spriter) {
+	if e, exists := h.entries[sp]; exists {
+		h.unlink(sp, e)
+		delete(h.entries, sp)
+	}
+}
+
+// Odebrání spritu ze všech jeho buněk
+func (h *SpatialHash) unlink(sp Spriter, e *hashEntry) {
+	for x := e.x0; x <= e.x1; x++ {
+		for y := e.y0; y <= e.y1; y++ {
+			k := cellKey{x, y}
+			cell := h.cells[k]
+			for i, c := range cell {
+				if c == sp {
+					cell[i] = cell[len(cell)-1]
+					cell[len(cell)-1] = nil
+					cell = cell[:len(cell)-1]
+					break
+				}
+			}
+			if len(cell) == 0 {
+				delete(h.cells, k)
+			} else {
+				h.cells[k] = cell
+			}
+		}
+	}
+}
+
+func (h *SpatialHash) Clear() {
+	h.cells = make(map[cellKey][]Spriter)
+	h.entries = make(map[Spriter]*hashEntry)
+}
+
+// Bounding box spritu tak, jak je uložený v indexu
+func (h *SpatialHash) Bounds(sp Spriter) (Rectangle, bool) {
+	e, exists := h.entries[sp]
+	if !exists {
+		return Rectangle{}, false
+	}
+	return e.bounds, true
+}
+
+func overlaps(a, b Rectangle) bool {
+	return a.Min.X <= b.Max.X && a.Max.X >= b.Min.X && a.Min.Y <= b.Max.Y && a.Max.Y >= b.Min.Y
+}
+
+// Funkce zavolá fn pro každý sprite, jehož bounding box zasahuje do r
+// Pokud fn vrátí false, dotaz se ukončí
+func (h *SpatialHash) Query(r Rectangle, fn func(Spriter) bool) {
+	h.stamp++
+
+	// Pro velké (i nekonečné) oblasti je rychlejší projít všechny záznamy než všechny buňky
+	w := math.Floor(r.Max.X/h.CellSize) - math.Floor(r.Min.X/h.CellSize) + 1
+	ht := math.Floor(r.Max.Y/h.CellSize) - math.Floor(r.Min.Y/h.CellSize) + 1
+	if w*ht > float64(len(h.entries)) {
+		for sp, e := range h.entries {
+			if overlaps(e.bounds, r) && !fn(sp) {
+				return
+			}
+		}
+		return
+	}
+
+	x0, y0, x1, y1 := h.cellRange(r)
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			for _, sp := range h.cells[cellKey{x, y}] {
+				e := h.entries[sp]
+				if e.stamp == h.stamp {
+					continue
+				}
+				e.stamp = h.stamp
+				if overlaps(e.bounds, r) && !fn(sp) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Funkce zavolá fn pro každý sprite v indexu (v nedefinovaném pořadí)
+func (h *SpatialHash) Each(fn func(Spriter)) {
+	for sp := range h.entries {
+		fn(sp)
+	}
+}
+
+func (h *SpatialHash) QueryRect(r Rectangle) []Spriter {
+	var ret []Spriter
+	h.Query(r, func(sp Spriter) bool {
+		ret = append(ret, sp)
+		return true
+	})
+	return ret
+}
+
+func (h *SpatialHash) QueryPoint(p Point) []Spriter {
+	var ret []Spriter
+	k := cellKey{int(math.Floor(p.X / h.CellSize)), int(math.Floor(p.Y / h.CellSize))}
+	for _, sp := range h.cells[k] {
+		if h.entries[sp].bounds.Contains(p) {
+			ret = append(ret, sp)
+		}
+	}
+	return ret
+}
+
+// Funkce zavolá fn pro každý pár spritů s překrývajícími se bounding boxy
+// Každý pár se ohlásí právě jednou - v první buňce, kterou oba sdílejí; pořadí párů není definované
+func (h *SpatialHash) Pairs(fn func(a, b Spriter)) {
+	for k, cell := range h.cells {
+		for i := 0; i < len(cell); i++ {
+			ea := h.entries[cell[i]]
+			for j := i + 1; j < len(cell); j++ {
+				eb := h.entries[cell[j]]
+				if max(ea.x0, eb.x0) != k.X || max(ea.y0, eb.y0) != k.Y {
+					continue
+				}
+				if overlaps(ea.bounds, eb.bounds) {
+					fn(cell[i], cell[j])
+				}
+			}
+		}
+	}
+}
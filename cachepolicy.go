@@ -0,0 +1,148 @@
+package main
+
+import "container/list"
+
+// Strategie výběru záznamu, který se z cache odstraní jako první
+// Všechny operace musí být O(1); FileCache volání serializuje, takže strategie nemusí být thread-safe
+// Připnuté záznamy FileCache ve strategii nejsou, takže je Victim nikdy nevrátí
+type EvictionPolicy interface {
+	Add(key string)         // nový záznam
+	Touch(key string)       // přístup k existujícímu záznamu
+	Remove(key string)      // záznam byl odstraněn nebo připnut
+	Victim() (string, bool) // záznam k odstranění; false, pokud žádný není
+	Clear()
+}
+
+// Odstraňuje nejdéle nepoužitý záznam
+type LRUPolicy struct {
+	order *list.List // na začátku naposledy použitý záznam
+	items map[string]*list.Element
+}
+
+func NewLRUPolicy() *LRUPolicy {
+	return &LRUPolicy{order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (p *LRUPolicy) Add(key string) {
+	if e, exists := p.items[key]; exists {
+		p.order.MoveToFront(e)
+		return
+	}
+	p.items[key] = p.order.PushFront(key)
+}
+
+func (p *LRUPolicy) Touch(key string) {
+	if e, exists := p.items[key]; exists {
+		p.order.MoveToFront(e)
+	}
+}
+
+func (p *LRUPolicy) Remove(key string) {
+	if e, exists := p.items[key]; exists {
+		p.order.Remove(e)
+		delete(p.items, key)
+	}
+}
+
+func (p *LRUPolicy) Victim() (string, bool) {
+	e := p.order.Back()
+	if e == nil {
+		return "", false
+	}
+	return e.Value.(string), true
+}
+
+func (p *LRUPolicy) Clear() {
+	p.order.Init()
+	clear(p.items)
+}
+
+// Odstraňuje nejméně často používaný záznam, při shodě ten nejdéle nepoužitý
+// Záznamy jsou ve skupinách podle počtu přístupů seřazených vzestupně, takže i Touch je O(1)
+type LFUPolicy struct {
+	buckets *list.List // *lfuBucket seřazené podle freq
+	items   map[string]*lfuItem
+}
+
+type lfuBucket struct {
+	freq  uint64
+	items *list.List // klíče, na začátku naposledy použitý
+}
+
+type lfuItem struct {
+	bucket *list.Element // prvek v LFUPolicy.buckets
+	elem   *list.Element // prvek v lfuBucket.items
+}
+
+func NewLFUPolicy() *LFUPolicy {
+	return &LFUPolicy{buckets: list.New(), items: make(map[string]*lfuItem)}
+}
+
+// Vložení klíče do skupiny s počtem freq, která má následovat hned po prvku after (nil = začátek)
+func (p *LFUPolicy) insert(key string, freq uint64, after *list.Element) *lfuItem {
+	var b *list.Element
+	switch {
+	case after == nil && p.buckets.Front() != nil && p.buckets.Front().Value.(*lfuBucket).freq == freq:
+		b = p.buckets.Front()
+	case after != nil && after.Next() != nil && after.Next().Value.(*lfuBucket).freq == freq:
+		b = after.Next()
+	case after == nil:
+		b = p.buckets.PushFront(&lfuBucket{freq: freq, items: list.New()})
+	default:
+		b = p.buckets.InsertAfter(&lfuBucket{freq: freq, items: list.New()}, after)
+	}
+	return &lfuItem{bucket: b, elem: b.Value.(*lfuBucket).items.PushFront(key)}
+}
+
+// Odebrání klíče ze skupiny; prázdná skupina zanikne
+func (p *LFUPolicy) unlink(it *lfuItem) {
+	b := it.bucket.Value.(*lfuBucket)
+	b.items.Remove(it.elem)
+	if b.items.Len() == 0 {
+		p.buckets.Remove(it.bucket)
+	}
+}
+
+func (p *LFUPolicy) Add(key string) {
+	if _, exists := p.items[key]; exists {
+		p.Touch(key)
+		return
+	}
+	p.items[key] = p.insert(key, 1, nil)
+}
+
+func (p *LFUPolicy) Touch(key string) {
+	it, exists := p.items[key]
+	if !exists {
+		return
+	}
+	b := it.bucket.Value.(*lfuBucket)
+	// Nová skupina se vkládá za starou; pokud stará zanikne, vloží se za její předchůdce
+	after := it.bucket
+	if b.items.Len() == 1 {
+		after = it.bucket.Prev()
+	}
+	freq := b.freq + 1
+	p.unlink(it)
+	p.items[key] = p.insert(key, freq, after)
+}
+
+func (p *LFUPolicy) Remove(key string) {
+	if it, exists := p.items[key]; exists {
+		p.unlink(it)
+		delete(p.items, key)
+	}
+}
+
+func (p *LFUPolicy) Victim() (string, bool) {
+	b := p.buckets.Front()
+	if b == nil {
+		return "", false
+	}
+	return b.Value.(*lfuBucket).items.Back().Value.(string), true
+}
+
+func (p *LFUPolicy) Clear() {
+	p.buckets.Init()
+	clear(p.items)
+}
@@ -0,0 +1,96 @@
+package main
+
+import "math"
+
+// Čas dotyku (0..1) obdélníku a, který se za krok posune o d, s nehybným obdélníkem b
+// Metoda rozšířeného obdélníku: b se zvětší o velikost a a testuje se paprsek z a.Min
+func sweptAABB(a Rectangle, d Vector, b Rectangle) (float64, bool) {
+	size := subPoints(a.Max, a.Min)
+	lo := Point{X: b.Min.X - size.X, Y: b.Min.Y - size.Y}
+	hi := b.Max
+
+	tmin, tmax := 0.0, 1.0
+	origin := [2]float64{a.Min.X, a.Min.Y}
+	dir := [2]float64{d.X, d.Y}
+	min := [2]float64{lo.X, lo.Y}
+	max := [2]float64{hi.X, hi.Y}
+
+	for i := 0; i < 2; i++ {
+		if math.Abs(dir[i]) < collisionEpsilon {
+			if origin[i] < min[i] || origin[i] > max[i] {
+				return 0, false
+			}
+			continue
+		}
+		t1 := (min[i] - origin[i]) / dir[i]
+		t2 := (max[i] - origin[i]) / dir[i]
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tmin = math.Max(tmin, t1)
+		tmax = math.Min(tmax, t2)
+		if tmin > tmax {
+			return 0, false
+		}
+	}
+	return tmin, true
+}
+
+// Funkce omezí posun těla s CCD na první dotyk s jiným tělem
+// Nejdřív se pomocí swept AABB vyberou překážky, do kterých se tělo může za krok trefit,
+// pak se pro ně přesně dopočítá okamžik dotyku podle kolizních tvarů
+// Překážky, se kterými se tělo už překrývá (např. podlaha, na které leží), pohyb nezastaví:
+// odebere se jen složka pohybu směrem do nich, takže tělo po nich může klouzat nebo se od nich vzdálit
+func (p *Physics) sweepMotion(sp Spriter, motion Vector) Vector {
+	if motion.Length() == 0 {
+		return motion
+	}
+
+	s := sp.GetSprite()
+	shape := s.Collider()
+	bounds := shape.Bounds()
+	moved := Rectangle{Min: bounds.Min.AddVector(motion), Max: bounds.Max.AddVector(motion)}
+	swept := BoundingBox([]Point{bounds.Min, bounds.Max, moved.Min, moved.Max})
+
+	type obstacle struct {
+		shape  Collider
+		bounds Rectangle
+	}
+	var obstacles []obstacle
+	p.Index.Query(swept, func(other Spriter) bool {
+		o := other.GetSprite()
+		if other == sp || o.Body.Sensor || !s.Body.CollidesWith(&o.Body) {
+			return true
+		}
+		// Tvar se bere aktuální, ne z indexu: sprity se během integrace kroku posouvají
+		oc := o.Collider()
+		if m, ok := CollideShapes(shape, oc); ok {
+			if d := motion.Dot(m.Normal); d > 0 {
+				motion = motion.Add(m.Normal.Scale(-d))
+			}
+			return true
+		}
+		obstacles = append(obstacles, obstacle{shape: oc, bounds: oc.Bounds()})
+		return true
+	})
+
+	dist := motion.Length()
+	if dist < collisionEpsilon {
+		return Vector{}
+	}
+	dir := motion.Scale(1 / dist)
+	best := dist
+	for _, o := range obstacles {
+		if toi, hit := sweptAABB(bounds, motion, o.bounds); !hit || toi*dist >= best {
+			continue
+		}
+
+		// Posun končí v místě prvního dotyku, takže řešič kolizí kontakt v tomto kroku uvidí
+		// a uplatní odraz i tření
+		if _, touch, _, ok := castShape(shape, dir, best, o.shape); ok && touch < best {
+			best = touch
+		}
+	}
+
+	return dir.Scale(best)
+}
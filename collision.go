@@ -0,0 +1,347 @@
+package main
+
+import "math"
+
+// Tolerance pro porovnávání při výpočtu kolizí
+const collisionEpsilon = 1e-9
+
+// Tvar pro detekci kolizí (Circle, OrientedRect, ConvexPolygon)
+type Collider interface {
+	Bounds() Rectangle
+	// Nový collider posunutý o offset a poté transformovaný maticí m (m může být nil)
+	Transform(offset Vector, m *Affine2D) Collider
+}
+
+// Výsledek kolize dvou tvarů
+type Manifold struct {
+	Normal Vector  // jednotkový vektor směrem od prvního tvaru k druhému
+	Depth  float64 // hloubka průniku ve směru Normal
+	Points []Point // body dotyku (1 nebo 2)
+}
+
+type Circle struct {
+	Center Point
+	Radius float64
+}
+
+// Otočený obdélník
+type OrientedRect struct {
+	Center   Point
+	HalfSize Vector
+	Angle    float64 // v radiánech
+}
+
+// Konvexní polygon; na směru navíjení vrcholů nezáleží
+type ConvexPolygon struct {
+	Points []Point
+}
+
+func (c Circle) Bounds() Rectangle {
+	return Rectangle{
+		Min: Point{X: c.Center.X - c.Radius, Y: c.Center.Y - c.Radius},
+		Max: Point{X: c.Center.X + c.Radius, Y: c.Center.Y + c.Radius},
+	}
+}
+
+func (c Circle) Transform(offset Vector, m *Affine2D) Collider {
+	ret := Circle{Center: c.Center.AddVector(offset), Radius: c.Radius}
+	if m != nil {
+		ret.Center = m.TransformPoint(ret.Center)
+		// Kruh zůstane kruhem jen při stejnoměrném měřítku, jinak bereme průměrné měřítko
+		ret.Radius *= math.Sqrt(math.Abs(m.Determinant()))
+	}
+	return ret
+}
+
+// Rohy obdélníku v pořadí kolem obvodu
+func (r OrientedRect) Corners() [4]Point {
+	cos, sin := math.Cos(r.Angle), math.Sin(r.Angle)
+	ax := Vector{X: cos * r.HalfSize.X, Y: sin * r.HalfSize.X}
+	ay := Vector{X: -sin * r.HalfSize.Y, Y: cos * r.HalfSize.Y}
+
+	return [4]Point{
+		r.Center.AddVector(ax.Scale(-1).Add(ay.Scale(-1))),
+		r.Center.AddVector(ax.Add(ay.Scale(-1))),
+		r.Center.AddVector(ax.Add(ay)),
+		r.Center.AddVector(ax.Scale(-1).Add(ay)),
+	}
+}
+
+func (r OrientedRect) Bounds() Rectangle {
+	c := r.Corners()
+	return BoundingBox(c[:])
+}
+
+func (r OrientedRect) Transform(offset Vector, m *Affine2D) Collider {
+	c := r.Corners()
+	return ConvexPolygon{Points: c[:]}.Transform(offset, m)
+}
+
+func (p ConvexPolygon) Bounds() Rectangle {
+	return BoundingBox(p.Points)
+}
+
+func (p ConvexPolygon) Transform(offset Vector, m *Affine2D) Collider {
+	pts := make([]Point, len(p.Points))
+	for i, pt := range p.Points {
+		pts[i] = pt.AddVector(offset)
+		if m != nil {
+			pts[i] = m.TransformPoint(pts[i])
+		}
+	}
+	return ConvexPolygon{Points: pts}
+}
+
+// Převod obdélníku na polygon (pro kolize s transformovanými sprity)
+func (r Rectangle) Polygon() ConvexPolygon {
+	return ConvexPolygon{Points: []Point{
+		{r.Min.X, r.Min.Y},
+		{r.Max.X, r.Min.Y},
+		{r.Max.X, r.Max.Y},
+		{r.Min.X, r.Max.Y},
+	}}
+}
+
+// Vrcholy tvaru, který je polygonem (u kruhu nil)
+func colliderVertices(c Collider) []Point {
+	switch s := c.(type) {
+	case ConvexPolygon:
+		return s.Points
+	case OrientedRect:
+		corners := s.Corners()
+		return corners[:]
+	}
+	return nil
+}
+
+func subPoints(a, b Point) Vector {
+	return Vector{X: a.X - b.X, Y: a.Y - b.Y}
+}
+
+func centroidOf(pts []Point) Point {
+	var c Point
+	for _, p := range pts {
+		c.X += p.X
+		c.Y += p.Y
+	}
+	n := float64(len(pts))
+	return Point{X: c.X / n, Y: c.Y / n}
+}
+
+func projectPoints(pts []Point, axis Vector) (min, max float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, p := range pts {
+		d := p.X*axis.X + p.Y*axis.Y
+		min = math.Min(min, d)
+		max = math.Max(max, d)
+	}
+	return min, max
+}
+
+// Funkce pro detekci kolize dvou tvarů metodou oddělujících os (SAT)
+func CollideShapes(a, b Collider) (Manifold, bool) {
+	ca, aCircle := a.(Circle)
+	cb, bCircle := b.(Circle)
+
+	switch {
+	case aCircle && bCircle:
+		return collideCircles(ca, cb)
+	case aCircle:
+		m, ok := collidePolygonCircle(colliderVertices(b), ca)
+		m.Normal = m.Normal.Scale(-1)
+		return m, ok
+	case bCircle:
+		return collidePolygonCircle(colliderVertices(a), cb)
+	default:
+		return collidePolygons(colliderVertices(a), colliderVertices(b))
+	}
+}
+
+func collideCircles(a, b Circle) (Manifold, bool) {
+	d := subPoints(b.Center, a.Center)
+	dist := d.Length()
+	r := a.Radius + b.Radius
+	if dist >= r {
+		return Manifold{}, false
+	}
+
+	n := Vector{X: 1}
+	if dist > collisionEpsilon {
+		n = d.Scale(1 / dist)
+	}
+	return Manifold{
+		Normal: n,
+		Depth:  r - dist,
+		Points: []Point{a.Center.AddVector(n.Scale(a.Radius))},
+	}, true
+}
+
+// Kolize polygonu (první) s kruhem (druhý)
+func collidePolygonCircle(poly []Point, c Circle) (Manifold, bool) {
+	if len(poly) == 0 {
+		return Manifold{}, false
+	}
+
+	best := Manifold{Depth: math.Inf(1)}
+	testAxis := func(axis Vector) bool {
+		axis = axis.Normalize()
+		if axis.Length() == 0 {
+			return true
+		}
+		pmin, pmax := projectPoints(poly, axis)
+		cc := c.Center.X*axis.X + c.Center.Y*axis.Y
+		overlap := math.Min(pmax-(cc-c.Radius), (cc+c.Radius)-pmin)
+		if overlap <= 0 {
+			return false
+		}
+		if overlap < best.Depth {
+			best.Depth = overlap
+			best.Normal = axis
+		}
+		return true
+	}
+
+	closest := poly[0]
+	for i := range poly {
+		p1, p2 := poly[i], poly[(i+1)%len(poly)]
+		if !testAxis(subPoints(p2, p1).Perpendicular()) {
+			return Manifold{}, false
+		}
+		if subPoints(poly[i], c.Center).Length() < subPoints(closest, c.Center).Length() {
+			closest = poly[i]
+		}
+	}
+	if !testAxis(subPoints(c.Center, closest)) {
+		return Manifold{}, false
+	}
+
+	// Normála musí mířit od polygonu ke kruhu
+	if subPoints(c.Center, centroidOf(poly)).Dot(best.Normal) < 0 {
+		best.Normal = best.Normal.Scale(-1)
+	}
+	best.Points = []Point{c.Center.AddVector(best.Normal.Scale(-c.Radius))}
+	return best, true
+}
+
+func collidePolygons(a, b []Point) (Manifold, bool) {
+	if len(a) == 0 || len(b) == 0 {
+		return Manifold{}, false
+	}
+
+	best := Manifold{Depth: math.Inf(1)}
+	for _, poly := range [][]Point{a, b} {
+		for i := range poly {
+			axis := subPoints(poly[(i+1)%len(poly)], poly[i]).Perpendicular().Normalize()
+			if axis.Length() == 0 {
+				continue
+			}
+			amin, amax := projectPoints(a, axis)
+			bmin, bmax := projectPoints(b, axis)
+			overlap := math.Min(amax-bmin, bmax-amin)
+			if overlap <= 0 {
+				return Manifold{}, false
+			}
+			if overlap < best.Depth {
+				best.Depth = overlap
+				best.Normal = axis
+			}
+		}
+	}
+
+	if subPoints(centroidOf(b), centroidOf(a)).Dot(best.Normal) < 0 {
+		best.Normal = best.Normal.Scale(-1)
+	}
+	best.Points = contactPoints(a, b, best.Normal)
+	return best, true
+}
+
+// Hrana polygonu nejvíce kolmá k normále, obsahující nejvzdálenější vrchol ve směru n
+type featureEdge struct {
+	max    Point // nejvzdálenější vrchol
+	v1, v2 Point
+}
+
+func (e featureEdge) dir() Vector {
+	return subPoints(e.v2, e.v1)
+}
+
+func bestEdge(poly []Point, n Vector) featureEdge {
+	idx := 0
+	maxProj := math.Inf(-1)
+	for i, p := range poly {
+		if d := p.X*n.X + p.Y*n.Y; d > maxProj {
+			maxProj = d
+			idx = i
+		}
+	}
+
+	v := poly[idx]
+	next := poly[(idx+1)%len(poly)]
+	prev := poly[(idx+len(poly)-1)%len(poly)]
+
+	// Obě hrany vedou k nejvzdálenějšímu vrcholu, kolmější hrana má skalární součin blíž nule
+	l := subPoints(v, next).Normalize()
+	r := subPoints(v, prev).Normalize()
+	if r.Dot(n) <= l.Dot(n) {
+		return featureEdge{max: v, v1: prev, v2: v}
+	}
+	return featureEdge{max: v, v1: v, v2: next}
+}
+
+// Ořezání úsečky v1-v2 na poloprostor, kde je projekce na n alespoň o
+func clipSegment(v1, v2 Point, n Vector, o float64) []Point {
+	var cp []Point
+	d1 := n.X*v1.X + n.Y*v1.Y - o
+	d2 := n.X*v2.X + n.Y*v2.Y - o
+	if d1 >= 0 {
+		cp = append(cp, v1)
+	}
+	if d2 >= 0 {
+		cp = append(cp, v2)
+	}
+	if d1*d2 < 0 {
+		e := subPoints(v2, v1)
+		u := d1 / (d1 - d2)
+		cp = append(cp, v1.AddVector(e.Scale(u)))
+	}
+	return cp
+}
+
+// Body dotyku dvou polygonů ořezáním dopadající hrany referenční hranou
+func contactPoints(a, b []Point, n Vector) []Point {
+	e1 := bestEdge(a, n)
+	e2 := bestEdge(b, n.Scale(-1))
+
+	ref, inc := e1, e2
+	refNormal := n
+	if math.Abs(e1.dir().Normalize().Dot(n)) > math.Abs(e2.dir().Normalize().Dot(n)) {
+		ref, inc = e2, e1
+		refNormal = n.Scale(-1)
+	}
+
+	rv := ref.dir().Normalize()
+	o1 := rv.X*ref.v1.X + rv.Y*ref.v1.Y
+	cp := clipSegment(inc.v1, inc.v2, rv, o1)
+	if len(cp) < 2 {
+		return []Point{e1.max}
+	}
+
+	o2 := rv.X*ref.v2.X + rv.Y*ref.v2.Y
+	cp = clipSegment(cp[0], cp[1], rv.Scale(-1), -o2)
+	if len(cp) < 2 {
+		return []Point{e1.max}
+	}
+
+	// Ponecháme jen body, které leží za referenční hranou
+	max := refNormal.X*ref.max.X + refNormal.Y*ref.max.Y
+	var pts []Point
+	for _, p := range cp[:2] {
+		if refNormal.X*p.X+refNormal.Y*p.Y-max <= collisionEpsilon {
+			pts = append(pts, p)
+		}
+	}
+	if len(pts) == 0 {
+		return []Point{e1.max}
+	}
+	return pts
+}
@@ -0,0 +1,97 @@
+package main
+
+import "sort"
+
+// Rozhraní, která může sprite implementovat, aby dostával události o kolizích
+// Normála v Manifold vždy míří od spritu, kterému se událost doručuje, k other
+type CollisionEnterer interface {
+	OnCollisionEnter(other Spriter, m Manifold)
+}
+
+type CollisionStayer interface {
+	OnCollisionStay(other Spriter, m Manifold)
+}
+
+type CollisionExiter interface {
+	OnCollisionExit(other Spriter)
+}
+
+// Dotýkající se pár spritů v aktuálním kroku
+type touch struct {
+	A, B     Spriter
+	Manifold Manifold
+}
+
+func (p *Physics) detectContacts() {
+	p.touches = p.touches[:0]
+	p.Contacts(func(a, b Spriter, m Manifold) {
+		p.touches = append(p.touches, touch{A: a, B: b, Manifold: m})
+	})
+}
+
+func flipManifold(m Manifold) Manifold {
+	m.Normal = m.Normal.Scale(-1)
+	return m
+}
+
+// Funkce porovná páry z tohoto a minulého kroku a rozešle enter/stay/exit události
+// Pořadí událostí je dané pořadím spritů ve scéně, takže je deterministické
+func (p *Physics) dispatchCollisionEvents() {
+	type exited struct {
+		pair  spritePair
+		order int
+	}
+	var gone []exited
+
+	current := make(map[spritePair]int, len(p.touches))
+	for i, t := range p.touches {
+		current[spritePair{A: t.A, B: t.B}] = i
+	}
+	for pair, order := range p.touching {
+		_, stays := current[pair]
+		if !stays {
+			_, stays = current[spritePair{A: pair.B, B: pair.A}]
+		}
+		if !stays {
+			gone = append(gone, exited{pair: pair, order: order})
+		}
+	}
+
+	// Exit nejdřív, aby hra viděla uvolnění dřív než případný nový kontakt
+	sort.Slice(gone, func(i, j int) bool {
+		return gone[i].order < gone[j].order
+	})
+	for _, g := range gone {
+		if e, ok := g.pair.A.(CollisionExiter); ok {
+			e.OnCollisionExit(g.pair.B)
+		}
+		if e, ok := g.pair.B.(CollisionExiter); ok {
+			e.OnCollisionExit(g.pair.A)
+		}
+	}
+
+	for _, t := range p.touches {
+		_, existed := p.touching[spritePair{A: t.A, B: t.B}]
+		if !existed {
+			_, existed = p.touching[spritePair{A: t.B, B: t.A}]
+		}
+
+		if existed {
+			if e, ok := t.A.(CollisionStayer); ok {
+				e.OnCollisionStay(t.B, t.Manifold)
+			}
+			if e, ok := t.B.(CollisionStayer); ok {
+				e.OnCollisionStay(t.A, flipManifold(t.Manifold))
+			}
+		} else {
+			if e, ok := t.A.(CollisionEnterer); ok {
+				e.OnCollisionEnter(t.B, t.Manifold)
+			}
+			if e, ok := t.B.(CollisionEnterer); ok {
+				e.OnCollisionEnter(t.A, flipManifold(t.Manifold))
+			}
+		}
+	}
+
+	p.touching = current
+}
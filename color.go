@@ -0,0 +1,456 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Barva v modelu HSV: odstín ve stupních 0..360, sytost, jas a alfa 0..1
+type HSV struct {
+	H, S, V, A float64
+}
+
+// Barva v modelu HSL: odstín ve stupních 0..360, sytost, světlost a alfa 0..1
+type HSL struct {
+	H, S, L, A float64
+}
+
+// Barva v percepčním prostoru OKLab: stejný rozdíl hodnot znamená zhruba stejný rozdíl pro oko
+// L je světlost 0..1, A a B jsou osy zelená-červená a modrá-žlutá (přibližně -0.4..0.4)
+type OKLab struct {
+	L, A, B, Alpha float64
+}
+
+func channel(c uint8) float64 {
+	return float64(c) / 255
+}
+
+func toChannel(x float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(x, 1)) * 255))
+}
+
+// Odstín 0..360 a chroma (rozdíl největší a nejmenší složky) pro HSV i HSL
+func hueChroma(r, g, b float64) (h, maxC, minC float64) {
+	maxC = math.Max(r, math.Max(g, b))
+	minC = math.Min(r, math.Min(g, b))
+	d := maxC - minC
+	switch {
+	case d == 0:
+		h = 0
+	case maxC == r:
+		h = math.Mod((g-b)/d, 6)
+	case maxC == g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, maxC, minC
+}
+
+// RGB z odstínu, chromy a posunu všech složek
+func hueToRGB(h, chroma, m float64) (r, g, b float64) {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	h /= 60
+	x := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+	switch int(h) {
+	case 0:
+		r, g, b = chroma, x, 0
+	case 1:
+		r, g, b = x, chroma, 0
+	case 2:
+		r, g, b = 0, chroma, x
+	case 3:
+		r, g, b = 0, x, chroma
+	case 4:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	return r + m, g + m, b + m
+}
+
+func HSVFromColor(c sdl.Color) HSV {
+	h, maxC, minC := hueChroma(channel(c.R), channel(c.G), channel(c.B))
+	s := 0.0
+	if maxC > 0 {
+		s = (maxC - minC) / maxC
+	}
+	return HSV{H: h, S: s, V: maxC, A: channel(c.A)}
+}
+
+func (h HSV) Color() sdl.Color {
+	chroma := h.V * h.S
+	r, g, b := hueToRGB(h.H, chroma, h.V-chroma)
+	return sdl.Color{R: toChannel(r), G: toChannel(g), B: toChannel(b), A: toChannel(h.A)}
+}
+
+func HSLFromColor(c sdl.Color) HSL {
+	h, maxC, minC := hueChroma(channel(c.R), channel(c.G), channel(c.B))
+	l := (maxC + minC) / 2
+	s := 0.0
+	if d := maxC - minC; d > 0 {
+		s = d / (1 - math.Abs(2*l-1))
+	}
+	return HSL{H: h, S: s, L: l, A: channel(c.A)}
+}
+
+func (h HSL) Color() sdl.Color {
+	chroma := (1 - math.Abs(2*h.L-1)) * h.S
+	r, g, b := hueToRGB(h.H, chroma, h.L-chroma/2)
+	return sdl.Color{R: toChannel(r), G: toChannel(g), B: toChannel(b), A: toChannel(h.A)}
+}
+
+// Převod složky sRGB na lineární intenzitu světla
+func srgbToLinear(x float64) float64 {
+	if x <= 0.04045 {
+		return x / 12.92
+	}
+	return math.Pow((x+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(x float64) float64 {
+	if x <= 0.0031308 {
+		return x * 12.92
+	}
+	return 1.055*math.Pow(x, 1/2.4) - 0.055
+}
+
+// Převod podle B. Ottossona (https://bottosson.github.io/posts/oklab/)
+func OKLabFromColor(c sdl.Color) OKLab {
+	r, g, b := srgbToLinear(channel(c.R)), srgbToLinear(channel(c.G)), srgbToLinear(channel(c.B))
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	return OKLab{
+		L:     0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A:     1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B:     0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+		Alpha: channel(c.A),
+	}
+}
+
+// Barvy mimo rozsah sRGB se ořežou
+func (o OKLab) Color() sdl.Color {
+	l := o.L + 0.3963377774*o.A + 0.2158037573*o.B
+	m := o.L - 0.1055613458*o.A - 0.0638541728*o.B
+	s := o.L - 0.0894841775*o.A - 1.2914855480*o.B
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	r := 4.0767416621*l - 3.3077115913*m + 0.2309699292*s
+	g := -1.2684380046*l + 2.6097574011*m - 0.3413193965*s
+	b := -0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+	return sdl.Color{
+		R: toChannel(linearToSRGB(r)),
+		G: toChannel(linearToSRGB(g)),
+		B: toChannel(linearToSRGB(b)),
+		A: toChannel(o.Alpha),
+	}
+}
+
+// Vzdálenost barev v OKLab (0 = stejné, přibližně 0.02 je hranice rozlišitelnosti)
+func ColorDistance(c1, c2 sdl.Color) float64 {
+	a, b := OKLabFromColor(c1), OKLabFromColor(c2)
+	return math.Sqrt((a.L-b.L)*(a.L-b.L) + (a.A-b.A)*(a.A-b.A) + (a.B-b.B)*(a.B-b.B))
+}
+
+// Prostá interpolace složek sRGB
+func LerpColor(a, b sdl.Color, t float64) sdl.Color {
+	return sdl.Color{
+		R: toChannel(lerp(channel(a.R), channel(b.R), t)),
+		G: toChannel(lerp(channel(a.G), channel(b.G), t)),
+		B: toChannel(lerp(channel(a.B), channel(b.B), t)),
+		A: toChannel(lerp(channel(a.A), channel(b.A), t)),
+	}
+}
+
+// Percepční interpolace v OKLab: přechod nemá tmavý ani šedý střed jako v sRGB
+func LerpColorOKLab(a, b sdl.Color, t float64) sdl.Color {
+	x, y := OKLabFromColor(a), OKLabFromColor(b)
+	return OKLab{
+		L:     lerp(x.L, y.L, t),
+		A:     lerp(x.A, y.A, t),
+		B:     lerp(x.B, y.B, t),
+		Alpha: lerp(x.Alpha, y.Alpha, t),
+	}.Color()
+}
+
+// Interpolace v HSV; odstín jde kratší cestou po kruhu
+func LerpColorHSV(a, b sdl.Color, t float64) sdl.Color {
+	x, y := HSVFromColor(a), HSVFromColor(b)
+	// Šedá nemá odstín, převezme ho od druhé barvy, aby přechod nešel přes červenou
+	if x.S == 0 {
+		x.H = y.H
+	}
+	if y.S == 0 {
+		y.H = x.H
+	}
+	hue := x.H + math.Remainder(y.H-x.H, 360)*t
+	return HSV{H: hue, S: lerp(x.S, y.S, t), V: lerp(x.V, y.V, t), A: lerp(x.A, y.A, t)}.Color()
+}
+
+// Zastávka barevného přechodu
+type ColorStop struct {
+	Pos   float64 // poloha 0..1
+	Color sdl.Color
+}
+
+// Barevný přechod interpolovaný v OKLab
+type Gradient struct {
+	Stops []ColorStop // seřazené podle Pos
+}
+
+// Funkce pro přechod s rovnoměrně rozmístěnými barvami
+func NewGradient(colors ...sdl.Color) Gradient {
+	g := Gradient{Stops: make([]ColorStop, len(colors))}
+	for i, c := range colors {
+		pos := 0.0
+		if len(colors) > 1 {
+			pos = float64(i) / float64(len(colors)-1)
+		}
+		g.Stops[i] = ColorStop{Pos: pos, Color: c}
+	}
+	return g
+}
+
+// Funkce pro přechod z libovolně rozmístěných zastávek
+func NewGradientStops(stops ...ColorStop) Gradient {
+	s := append([]ColorStop(nil), stops...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Pos < s[j].Pos })
+	return Gradient{Stops: s}
+}
+
+// Barva přechodu v poloze t; mimo rozsah zastávek se použije krajní barva
+func (g Gradient) At(t float64) sdl.Color {
+	if len(g.Stops) == 0 {
+		return sdl.Color{}
+	}
+	i := sort.Search(len(g.Stops), func(i int) bool { return g.Stops[i].Pos > t })
+	if i == 0 {
+		return g.Stops[0].Color
+	}
+	if i == len(g.Stops) {
+		return g.Stops[i-1].Color
+	}
+	a, b := g.Stops[i-1], g.Stops[i]
+	return LerpColorOKLab(a.Color, b.Color, (t-a.Pos)/(b.Pos-a.Pos))
+}
+
+// Předem vypočtená tabulka n barev přechodu (pro částice a efekty, které barvu hledají každý snímek)
+func (g Gradient) Table(n int) []sdl.Color {
+	ret := make([]sdl.Color, n)
+	for i := range ret {
+		t := 0.0
+		if n > 1 {
+			t = float64(i) / float64(n-1)
+		}
+		ret[i] = g.At(t)
+	}
+	return ret
+}
+
+// Barva se složkami vynásobenými alfou (pro míchání s sdl.BLENDMODE_BLEND bez tmavých okrajů)
+func Premultiply(c sdl.Color) sdl.Color {
+	a := uint16(c.A)
+	return sdl.Color{
+		R: uint8((uint16(c.R)*a + 127) / 255),
+		G: uint8((uint16(c.G)*a + 127) / 255),
+		B: uint8((uint16(c.B)*a + 127) / 255),
+		A: c.A,
+	}
+}
+
+// Opak Premultiply; u průhledné barvy se původní složky ztratily a vrací se černá
+func Unpremultiply(c sdl.Color) sdl.Color {
+	if c.A == 0 {
+		return sdl.Color{}
+	}
+	a := uint16(c.A)
+	return sdl.Color{
+		R: uint8(min((uint16(c.R)*255+a/2)/a, 255)),
+		G: uint8(min((uint16(c.G)*255+a/2)/a, 255)),
+		B: uint8(min((uint16(c.B)*255+a/2)/a, 255)),
+		A: c.A,
+	}
+}
+
+// Složení premultiplikované barvy src přes dst (operátor "over")
+func BlendPremultiplied(dst, src sdl.Color) sdl.Color {
+	inv := 255 - uint16(src.A)
+	over := func(d, s uint8) uint8 {
+		return uint8(min(uint16(s)+(uint16(d)*inv+127)/255, 255))
+	}
+	return sdl.Color{R: over(dst.R, src.R), G: over(dst.G, src.G), B: over(dst.B, src.B), A: over(dst.A, src.A)}
+}
+
+// Barva s jinou průhledností (0..1), např. pro postupné mizení
+func Fade(c sdl.Color, alpha float64) sdl.Color {
+	c.A = toChannel(alpha)
+	return c
+}
+
+// Obarvení: složky se vynásobí barvou tint (jako sdl.Texture.SetColorMod)
+func Tint(c, tint sdl.Color) sdl.Color {
+	mul := func(a, b uint8) uint8 {
+		return uint8((uint16(a)*uint16(b) + 127) / 255)
+	}
+	return sdl.Color{R: mul(c.R, tint.R), G: mul(c.G, tint.G), B: mul(c.B, tint.B), A: mul(c.A, tint.A)}
+}
+
+// Funkce pro převod zápisu RGB, RRGGBB nebo RRGGBBAA (volitelně s # na začátku) na barvu
+func ParseHexColor(s string) (sdl.Color, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 && len(s) != 8 {
+		return sdl.Color{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return sdl.Color{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	if len(s) == 6 {
+		v = v<<8 | 0xff
+	}
+	return sdl.Color{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// Zápis barvy jako #RRGGBB, nebo #RRGGBBAA u průhledné barvy
+func ColorHex(c sdl.Color) string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// Pojmenovaná paleta barev
+type Palette struct {
+	Name   string
+	Colors []sdl.Color
+	Names  []string // jména barev (jen z .gpl), "" pokud barva jméno nemá
+}
+
+// Funkce pro načtení palety ze souboru .hex (barva na řádek) nebo .gpl (GIMP)
+func LoadPalette(path string) (*Palette, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ext := strings.ToLower(filepath.Ext(path))
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	switch ext {
+	case ".hex":
+		return ParseHexPalette(name, f)
+	case ".gpl":
+		return ParseGPLPalette(f)
+	default:
+		return nil, fmt.Errorf("unsupported palette format %q", ext)
+	}
+}
+
+// Funkce pro čtení palety ve formátu .hex (např. z Lospec): jedna barva RRGGBB na řádek
+func ParseHexPalette(name string, r io.Reader) (*Palette, error) {
+	p := &Palette{Name: name}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, ";") {
+			continue
+		}
+		c, err := ParseHexColor(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		p.Colors = append(p.Colors, c)
+		p.Names = append(p.Names, "")
+	}
+	return p, scanner.Err()
+}
+
+// Funkce pro čtení palety GIMP (.gpl): hlavička "GIMP Palette", volitelně "Name:" a "Columns:",
+// pak řádky "R G B [jméno]"; řádky začínající # jsou komentáře
+func ParseGPLPalette(r io.Reader) (*Palette, error) {
+	p := &Palette{}
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "GIMP Palette" {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("missing GIMP Palette header")
+	}
+
+	for line := 2; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#"):
+			continue
+		case strings.HasPrefix(text, "Name:"):
+			p.Name = strings.TrimSpace(strings.TrimPrefix(text, "Name:"))
+			continue
+		case strings.HasPrefix(text, "Columns:"):
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: expected R G B values", line)
+		}
+		var rgb [3]uint8
+		for i := range rgb {
+			v, err := strconv.ParseUint(fields[i], 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid color component %q", line, fields[i])
+			}
+			rgb[i] = uint8(v)
+		}
+		p.Colors = append(p.Colors, sdl.Color{R: rgb[0], G: rgb[1], B: rgb[2], A: 255})
+		p.Names = append(p.Names, strings.Join(fields[3:], " "))
+	}
+	return p, scanner.Err()
+}
+
+// Barva podle jména z palety
+func (p *Palette) Color(name string) (sdl.Color, bool) {
+	for i, n := range p.Names {
+		if n != "" && strings.EqualFold(n, name) {
+			return p.Colors[i], true
+		}
+	}
+	return sdl.Color{}, false
+}
+
+// Nejbližší barva palety (v OKLab), např. pro omezení efektů na barvy pixel-art palety
+// Alfa se zachová z původní barvy
+func (p *Palette) Nearest(c sdl.Color) sdl.Color {
+	if len(p.Colors) == 0 {
+		return c
+	}
+	best, bestDist := p.Colors[0], math.Inf(1)
+	for _, pc := range p.Colors {
+		if d := ColorDistance(c, pc); d < bestDist {
+			best, bestDist = pc, d
+		}
+	}
+	best.A = c.A
+	return best
+}
@@ -0,0 +1,222 @@
+package main
+
+import "math"
+
+// Ovladač postavy pro plošinovky
+// Postava je kinematický sprite: ovladač naplánuje pohyb tažením jejího tvaru scénou
+// (move and slide) a výsledný posun jí nastaví jako Movement, takže ho fyzika v kroku přesně provede
+// Update se volá jednou za tick (typicky z Tick spritu) s délkou kroku herní smyčky
+type CharacterController struct {
+	Sprite *Sprite
+	Scene  *Scene
+	Mask   uint32 // kategorie, o které se postava zastaví
+
+	Gravity      float64 // zrychlení pádu (kladné Y míří dolů)
+	MoveSpeed    float64 // maximální rychlost chůze
+	Acceleration float64 // zrychlení chůze, 0 = okamžitě
+	JumpSpeed    float64 // počáteční rychlost skoku
+	JumpCutoff   float64 // násobek rychlosti při předčasném puštění skoku (variabilní výška skoku)
+	MaxFallSpeed float64
+	MaxSlope     float64 // nejstrmější svah v radiánech, po kterém lze chodit
+	CoyoteTime   float64 // jak dlouho po opuštění hrany lze ještě skočit (s)
+	JumpBuffer   float64 // jak dlouho předem se pamatuje stisk skoku (s)
+	SnapDistance float64 // do jaké vzdálenosti se postava přichytí k zemi při chůzi z kopce
+	SkinWidth    float64 // mezera, kterou postava drží od povrchů
+
+	Velocity     Vector
+	Grounded     bool
+	GroundNormal Vector
+	Ground       Spriter // sprite, na kterém postava stojí
+	WallLeft     bool
+	WallRight    bool
+	Ceiling      bool
+
+	coyote   float64
+	buffered float64
+	jumping  bool
+}
+
+func NewCharacterController(s *Sprite, scene *Scene) *CharacterController {
+	s.Body.Mode = Kinematic
+	return &CharacterController{
+		Sprite:       s,
+		Scene:        scene,
+		Mask:         MaskAll,
+		Gravity:      1800,
+		MoveSpeed:    220,
+		Acceleration: 2000,
+		JumpSpeed:    620,
+		JumpCutoff:   0.45,
+		MaxFallSpeed: 1200,
+		MaxSlope:     DegreesToRadians(50),
+		CoyoteTime:   0.1,
+		JumpBuffer:   0.12,
+		SnapDistance: 6,
+		SkinWidth:    0.05,
+	}
+}
+
+// Maximální počet odrazů při klouzání po površích v jednom kroku
+const controllerSlides = 4
+
+// Funkce pro jeden krok ovladače
+// move je vstup chůze -1..1, jumpPressed stisk skoku v tomto ticku, jumpHeld držení tlačítka skoku
+func (c *CharacterController) Update(dt float64, move float64, jumpPressed, jumpHeld bool) {
+	start := c.Sprite.Rect.Min
+
+	// Horizontální pohyb
+	target := move * c.MoveSpeed
+	if c.Acceleration <= 0 {
+		c.Velocity.X = target
+	} else {
+		step := c.Acceleration * dt
+		c.Velocity.X += math.Max(-step, math.Min(target-c.Velocity.X, step))
+	}
+
+	// Coyote time a buffer skoku
+	if c.Grounded {
+		c.coyote = c.CoyoteTime
+		c.jumping = false
+	} else {
+		c.coyote -= dt
+	}
+	if jumpPressed {
+		c.buffered = c.JumpBuffer
+	} else {
+		c.buffered -= dt
+	}
+
+	if c.buffered > 0 && (c.Grounded || c.coyote > 0) {
+		c.Velocity.Y = -c.JumpSpeed
+		c.jumping = true
+		c.Grounded = false
+		c.coyote = 0
+		c.buffered = 0
+	}
+
+	// Variabilní výška skoku: po puštění tlačítka se stoupání zkrátí
+	if c.jumping && !jumpHeld && c.Velocity.Y < 0 {
+		c.Velocity.Y *= c.JumpCutoff
+		c.jumping = false
+	}
+
+	if !c.Grounded {
+		c.Velocity.Y = math.Min(c.Velocity.Y+c.Gravity*dt, c.MaxFallSpeed)
+	}
+
+	// Na zemi se chodí po tečně povrchu, aby postava na svahu neklouzala a neodskakovala
+	var motion Vector
+	if c.Grounded {
+		motion = c.GroundNormal.Perpendicular().Scale(c.Velocity.X * dt)
+		c.Velocity.Y = 0
+	} else {
+		motion = c.Velocity.Scale(dt)
+	}
+
+	wasGrounded := c.Grounded
+	c.Grounded, c.WallLeft, c.WallRight, c.Ceiling = false, false, false, false
+	c.Ground = nil
+
+	c.slide(motion)
+
+	// Přichycení k zemi při chůzi z kopce nebo přes hranu schodu
+	if wasGrounded && !c.Grounded && c.Velocity.Y >= 0 && c.SnapDistance > 0 {
+		if hit, ok := c.cast(Vector{Y: 1}, c.SnapDistance); ok && c.isGround(hit.Normal) {
+			c.Sprite.Translate(Vector{Y: math.Max(hit.Distance-c.SkinWidth, 0)})
+			c.land(hit)
+		}
+	}
+
+	// Kontrola země pod postavou, pokud stojí (pohyb do strany ji nemusel zjistit)
+	if !c.Grounded && c.Velocity.Y >= 0 {
+		if hit, ok := c.cast(Vector{Y: 1}, 2*c.SkinWidth); ok && c.isGround(hit.Normal) {
+			c.land(hit)
+		}
+	}
+
+	c.commit(start, dt)
+}
+
+// Move and slide: posun po povrchu se zbytkem pohybu po každém nárazu
+func (c *CharacterController) slide(motion Vector) {
+	for i := 0; i < controllerSlides; i++ {
+		dist := motion.Length()
+		if dist < collisionEpsilon {
+			return
+		}
+		dir := motion.Scale(1 / dist)
+
+		hit, ok := c.cast(dir, dist+c.SkinWidth)
+		if !ok {
+			c.Sprite.Translate(motion)
+			return
+		}
+
+		travel := math.Max(hit.Distance-c.SkinWidth, 0)
+		c.Sprite.Translate(dir.Scale(travel))
+
+		n := hit.Normal
+		switch {
+		case c.isGround(n):
+			c.land(hit)
+		case n.Y > math.Cos(c.MaxSlope):
+			c.Ceiling = true
+			c.Velocity.Y = math.Max(c.Velocity.Y, 0)
+		case n.X > 0:
+			c.WallLeft = true
+		default:
+			c.WallRight = true
+		}
+
+		// Odstranění složky rychlosti a zbytku pohybu směrem do povrchu
+		if vn := c.Velocity.Dot(n); vn < 0 {
+			c.Velocity = c.Velocity.Add(n.Scale(-vn))
+		}
+		rest := motion.Add(dir.Scale(-travel))
+		motion = rest.Add(n.Scale(-rest.Dot(n)))
+	}
+}
+
+func (c *CharacterController) isGround(n Vector) bool {
+	return -n.Y >= math.Cos(c.MaxSlope)
+}
+
+func (c *CharacterController) land(hit RayHit) {
+	c.Grounded = true
+	c.GroundNormal = hit.Normal
+	c.Ground = hit.Sprite
+	c.Velocity.Y = math.Min(c.Velocity.Y, 0)
+}
+
+// Tažení tvaru postavy; jednosměrné plošiny zastaví postavu jen při pohybu dolů,
+// pokud na začátku byla celá nad nimi
+func (c *CharacterController) cast(dir Vector, dist float64) (RayHit, bool) {
+	shape := c.Sprite.Collider()
+	bottom := shape.Bounds().Max.Y
+
+	return c.Scene.ShapeCastFunc(shape, dir, dist, func(sp Spriter) bool {
+		o := sp.GetSprite()
+		if o == c.Sprite || o.Body.Sensor || o.Body.Category&c.Mask == 0 {
+			return false
+		}
+		if o.Body.OneWay {
+			return dir.Y > 0 && bottom <= o.Collider().Bounds().Min.Y+c.SkinWidth
+		}
+		return true
+	})
+}
+
+// Předání naplánovaného posunu fyzice; bez fyziky zůstane sprite rovnou na novém místě
+func (c *CharacterController) commit(start Point, dt float64) {
+	d := subPoints(c.Sprite.Rect.Min, start)
+	if dt <= 0 {
+		c.Sprite.Movement = Vector{}
+		return
+	}
+	c.Sprite.Movement = d.Scale(1 / dt)
+
+	if c.Scene.Physics != nil {
+		// Posun provede ApplyPhysics kinematického těla v kroku fyziky
+		c.Sprite.Translate(d.Scale(-1))
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// Parametrická křivka s parametrem t v intervalu 0..1
+type Curve interface {
+	At(t float64) Point
+	Derivative(t float64) Vector // derivace podle t (není jednotková)
+}
+
+// Kvadratická Bézierova křivka
+type QuadraticBezier struct {
+	P0, P1, P2 Point
+}
+
+func (b QuadraticBezier) At(t float64) Point {
+	u := 1 - t
+	return weighted([]Point{b.P0, b.P1, b.P2}, []float64{u * u, 2 * u * t, t * t})
+}
+
+func (b QuadraticBezier) Derivative(t float64) Vector {
+	u := 1 - t
+	return subPoints(b.P1, b.P0).Scale(2 * u).Add(subPoints(b.P2, b.P1).Scale(2 * t))
+}
+
+// Kubická Bézierova křivka
+type CubicBezier struct {
+	P0, P1, P2, P3 Point
+}
+
+func (b CubicBezier) At(t float64) Point {
+	u := 1 - t
+	return weighted([]Point{b.P0, b.P1, b.P2, b.P3}, []float64{u * u * u, 3 * u * u * t, 3 * u * t * t, t * t * t})
+}
+
+func (b CubicBezier) Derivative(t float64) Vector {
+	u := 1 - t
+	return subPoints(b.P1, b.P0).Scale(3 * u * u).
+		Add(subPoints(b.P2, b.P1).Scale(6 * u * t)).
+		Add(subPoints(b.P3, b.P2).Scale(3 * t * t))
+}
+
+// Catmull-Rom spline: prochází všemi body, t se rozdělí rovnoměrně mezi úseky
+type CatmullRom struct {
+	Points []Point
+	Closed bool
+}
+
+func (c CatmullRom) At(t float64) Point {
+	p, u := splineSegment(c.Points, c.Closed, 1, t)
+	u2, u3 := u*u, u*u*u
+	return weighted(p[:], []float64{
+		0.5 * (-u3 + 2*u2 - u),
+		0.5 * (3*u3 - 5*u2 + 2),
+		0.5 * (-3*u3 + 4*u2 + u),
+		0.5 * (u3 - u2),
+	})
+}
+
+func (c CatmullRom) Derivative(t float64) Vector {
+	p, u := splineSegment(c.Points, c.Closed, 1, t)
+	u2 := u * u
+	v := weightedVector(p[:], []float64{
+		0.5 * (-3*u2 + 4*u - 1),
+		0.5 * (9*u2 - 10*u),
+		0.5 * (-9*u2 + 8*u + 1),
+		0.5 * (3*u2 - 2*u),
+	})
+	return v.Scale(float64(splineSegments(len(c.Points), c.Closed, 1)))
+}
+
+// Uniformní kubický B-spline: hladší než Catmull-Rom, ale řídicími body neprochází
+// (u otevřené křivky začíná a končí v krajních bodech)
+type BSpline struct {
+	Points []Point
+	Closed bool
+}
+
+func (b BSpline) At(t float64) Point {
+	p, u := splineSegment(b.Points, b.Closed, 2, t)
+	v := 1 - u
+	return weighted(p[:], []float64{
+		v * v * v / 6,
+		(3*u*u*u - 6*u*u + 4) / 6,
+		(-3*u*u*u + 3*u*u + 3*u + 1) / 6,
+		u * u * u / 6,
+	})
+}
+
+func (b BSpline) Derivative(t float64) Vector {
+	p, u := splineSegment(b.Points, b.Closed, 2, t)
+	v := 1 - u
+	d := weightedVector(p[:], []float64{
+		-v * v / 2,
+		(3*u*u - 4*u) / 2,
+		(-3*u*u + 2*u + 1) / 2,
+		u * u / 2,
+	})
+	return d.Scale(float64(splineSegments(len(b.Points), b.Closed, 2)))
+}
+
+// Počet úseků spline; pad je počet opakování krajního bodu na každé straně otevřené křivky
+func splineSegments(n int, closed bool, pad int) int {
+	if closed {
+		return n
+	}
+	return max(n+2*pad-3, 1)
+}
+
+// Čtyři řídicí body úseku, do kterého spadá t, a lokální parametr v úseku
+func splineSegment(points []Point, closed bool, pad int, t float64) ([4]Point, float64) {
+	var ret [4]Point
+	n := len(points)
+	if n == 0 {
+		return ret, 0
+	}
+
+	segs := splineSegments(n, closed, pad)
+	t = math.Max(0, math.Min(t, 1)) * float64(segs)
+	i := min(int(t), segs-1)
+	u := t - float64(i)
+
+	for k := 0; k < 4; k++ {
+		if closed {
+			ret[k] = points[((i+k-1)%n+n)%n]
+		} else {
+			// Krajní body se opakují, aby otevřená křivka začala a skončila v nich
+			ret[k] = points[max(0, min(i+k-pad, n-1))]
+		}
+	}
+	return ret, u
+}
+
+func weighted(pts []Point, w []float64) Point {
+	var ret Point
+	for i, p := range pts {
+		ret.X += p.X * w[i]
+		ret.Y += p.Y * w[i]
+	}
+	return ret
+}
+
+func weightedVector(pts []Point, w []float64) Vector {
+	p := weighted(pts, w)
+	return Vector{X: p.X, Y: p.Y}
+}
+
+// Jednotková tečna křivky
+func CurveTangent(c Curve, t float64) Vector {
+	d := c.Derivative(t)
+	if d.Length() < collisionEpsilon {
+		// V bodě s nulovou derivací (např. shodné řídicí body) se podíváme kousek vedle
+		a, b := c.At(math.Max(t-1e-4, 0)), c.At(math.Min(t+1e-4, 1))
+		d = subPoints(b, a)
+	}
+	return d.Normalize()
+}
+
+// Jednotková normála křivky (tečna otočená o 90°)
+func CurveNormal(c Curve, t float64) Vector {
+	return CurveTangent(c, t).Perpendicular()
+}
+
+// Maximální hloubka dělení při zploštění
+const flattenDepth = 16
+
+// Převod křivky na lomenou čáru; odchylka od křivky nepřesáhne tolerance
+func FlattenCurve(c Curve, tolerance float64) []Point {
+	if tolerance <= 0 {
+		tolerance = 0.25
+	}
+	pts := []Point{c.At(0)}
+	// Křivka se nejdřív rozdělí na několik dílů, aby se nepřehlédly smyčky
+	const initial = 8
+	for i := 0; i < initial; i++ {
+		t0, t1 := float64(i)/initial, float64(i+1)/initial
+		pts = flattenRange(c, t0, c.At(t0), t1, c.At(t1), tolerance, flattenDepth, pts)
+	}
+	return pts
+}
+
+func flattenRange(c Curve, t0 float64, p0 Point, t1 float64, p1 Point, tolerance float64, depth int, pts []Point) []Point {
+	tm := (t0 + t1) / 2
+	pm := c.At(tm)
+	if depth == 0 || (Segment{A: p0, B: p1}).Distance(pm) <= tolerance {
+		return append(pts, p1)
+	}
+	pts = flattenRange(c, t0, p0, tm, pm, tolerance, depth-1, pts)
+	return flattenRange(c, tm, pm, t1, p1, tolerance, depth-1, pts)
+}
+
+// Parametrizace křivky délkou oblouku (tabulka délek v rovnoměrných vzorcích t)
+type ArcLength struct {
+	Curve   Curve
+	params  []float64
+	lengths []float64
+}
+
+// Výchozí počet vzorků tabulky délek
+const DefaultArcSamples = 128
+
+func NewArcLength(c Curve, samples int) *ArcLength {
+	if samples < 1 {
+		samples = DefaultArcSamples
+	}
+	a := &ArcLength{
+		Curve:   c,
+		params:  make([]float64, samples+1),
+		lengths: make([]float64, samples+1),
+	}
+	prev := c.At(0)
+	for i := 1; i <= samples; i++ {
+		t := float64(i) / float64(samples)
+		p := c.At(t)
+		a.params[i] = t
+		a.lengths[i] = a.lengths[i-1] + subPoints(p, prev).Length()
+		prev = p
+	}
+	return a
+}
+
+// Celková délka křivky
+func (a *ArcLength) Length() float64 {
+	return a.lengths[len(a.lengths)-1]
+}
+
+// Parametr t, ve kterém je vzdálenost po křivce od začátku rovna dist
+func (a *ArcLength) Param(dist float64) float64 {
+	if dist <= 0 {
+		return 0
+	}
+	if dist >= a.Length() {
+		return 1
+	}
+	i := sort.SearchFloat64s(a.lengths, dist)
+	l0, l1 := a.lengths[i-1], a.lengths[i]
+	f := 0.0
+	if l1 > l0 {
+		f = (dist - l0) / (l1 - l0)
+	}
+	return a.params[i-1] + f*(a.params[i]-a.params[i-1])
+}
+
+// Bod ve vzdálenosti dist po křivce
+func (a *ArcLength) PointAt(dist float64) Point {
+	return a.Curve.At(a.Param(dist))
+}
+
+func (a *ArcLength) TangentAt(dist float64) Vector {
+	return CurveTangent(a.Curve, a.Param(dist))
+}
+
+// Pohyb spritu konstantní rychlostí po křivce (choreografie postav)
+// Střed spritu se v každém Update přesune na křivku; s Rotate se sprite natáčí po tečně
+type PathFollower struct {
+	Sprite   *Sprite
+	Path     *ArcLength
+	Speed    float64 // jednotek za sekundu
+	Distance float64 // ujetá vzdálenost po křivce
+	Loop     bool    // na konci začne znovu od začátku
+	PingPong bool    // na konci se otočí a jede zpět
+	Rotate   bool
+
+	reverse bool
+}
+
+func NewPathFollower(s *Sprite, c Curve, speed float64) *PathFollower {
+	return &PathFollower{Sprite: s, Path: NewArcLength(c, DefaultArcSamples), Speed: speed}
+}
+
+// Sprite dojel na konec křivky (jen bez Loop a PingPong)
+func (f *PathFollower) Done() bool {
+	return !f.Loop && !f.PingPong && f.Distance >= f.Path.Length()
+}
+
+func (f *PathFollower) Update(dt float64) {
+	length := f.Path.Length()
+	step := f.Speed * dt
+	if f.reverse {
+		step = -step
+	}
+	f.Distance += step
+
+	switch {
+	case length <= 0:
+		f.Distance = 0
+	case f.PingPong:
+		for f.Distance > length || f.Distance < 0 {
+			if f.Distance > length {
+				f.Distance = 2*length - f.Distance
+			} else {
+				f.Distance = -f.Distance
+			}
+			f.reverse = !f.reverse
+		}
+	case f.Loop:
+		f.Distance = math.Mod(f.Distance, length)
+		if f.Distance < 0 {
+			f.Distance += length
+		}
+	default:
+		f.Distance = math.Max(0, math.Min(f.Distance, length))
+	}
+
+	target := f.Path.PointAt(f.Distance)
+	f.Sprite.Translate(subPoints(target, f.Sprite.Rect.Center()))
+
+	if f.Rotate {
+		t := f.Path.TangentAt(f.Distance)
+		if f.reverse {
+			t = t.Scale(-1)
+		}
+		f.Sprite.Matrix = RotationAroundAffine(target, math.Atan2(t.Y, t.X))
+	}
+}
@@ -7,18 +7,42 @@ import (
 
 // Definice struktury pro FileCache
 type FileCache struct {
-	cache       map[string][]byte
-	order       []string // Seznam klíčů pro správu FIFO
+	cache       map[string]*cacheEntry
+	policy      EvictionPolicy // Strategie odstraňování záznamů (výchozí LRU)
 	mu          sync.Mutex
 	maxSize     int64 // Maximální velikost cache
 	currentSize int64 // Aktuální velikost cache
+
+	pinned                  int // počet připnutých záznamů
+	hits, misses, evictions uint64
+}
+
+type cacheEntry struct {
+	data   []byte
+	pinned bool // připnutý záznam se nikdy neodstraní kvůli místu
 }
 
-// Funkce pro vytvoření nového FileCache
+// Statistiky cache pro monitorování
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+	Pinned    int
+	Size      int64 // aktuální velikost v bajtech
+	MaxSize   int64
+}
+
+// Funkce pro vytvoření nového FileCache s odstraňováním nejdéle nepoužitých souborů (LRU)
 func NewFileCache(maxSize int64) *FileCache {
+	return NewFileCacheWithPolicy(maxSize, NewLRUPolicy())
+}
+
+// Funkce pro vytvoření FileCache s vlastní strategií odstraňování (např. NewLFUPolicy)
+func NewFileCacheWithPolicy(maxSize int64, policy EvictionPolicy) *FileCache {
 	return &FileCache{
-		cache:   make(map[string][]byte),
-		order:   []string{},
+		cache:   make(map[string]*cacheEntry),
+		policy:  policy,
 		maxSize: maxSize,
 	}
 }
@@ -29,7 +53,7 @@ func (fc *FileCache) LoadFile(path string) ([]byte, error) {
 	defer fc.mu.Unlock()
 
 	// Zkontroluj, jestli je soubor již v cache
-	if data, exists := fc.cache[path]; exists {
+	if data, exists := fc.lookup(path); exists {
 		return data, nil
 	}
 
@@ -42,15 +66,15 @@ func (fc *FileCache) LoadFile(path string) ([]byte, error) {
 	// Zjisti velikost nového záznamu
 	newSize := int64(len(data))
 
-	// Pokud překročíme maximální velikost, odstraň nejstarší záznamy
-	for fc.currentSize+newSize > fc.maxSize && len(fc.order) > 0 {
-		fc.removeOldest()
+	// Pokud překročíme maximální velikost, odstraň záznamy podle strategie
+	// Připnuté záznamy zůstanou, i kdyby se limit nepodařilo dodržet
+	for fc.currentSize+newSize > fc.maxSize && fc.evictOne() {
 	}
 
 	// Ulož data do cache
-	fc.cache[path] = data
-	fc.order = append(fc.order, path) // Přidej klíč do pořadí
-	fc.currentSize += newSize         // Aktualizuj aktuální velikost
+	fc.cache[path] = &cacheEntry{data: data}
+	fc.policy.Add(path)
+	fc.currentSize += newSize // Aktualizuj aktuální velikost
 
 	return data, nil
 }
@@ -60,43 +84,113 @@ func (fc *FileCache) GetFile(path string) ([]byte, bool) {
 	fc.mu.Lock()
 	defer fc.mu.Unlock()
 
-	data, exists := fc.cache[path]
-	return data, exists
+	return fc.lookup(path)
+}
+
+// Vyhledání záznamu se započtením zásahu nebo minutí
+func (fc *FileCache) lookup(path string) ([]byte, bool) {
+	e, exists := fc.cache[path]
+	if !exists {
+		fc.misses++
+		return nil, false
+	}
+	fc.hits++
+	if !e.pinned {
+		fc.policy.Touch(path)
+	}
+	return e.data, true
 }
 
-// Funkce pro odstranění souboru z cache
+// Funkce pro odstranění souboru z cache (i připnutého)
 func (fc *FileCache) RemoveFile(path string) bool {
 	fc.mu.Lock()
 	defer fc.mu.Unlock()
 
-	// Zkontroluj, jestli soubor existuje v cache
-	if data, exists := fc.cache[path]; exists {
-		// Odeber soubor z cache a aktualizuj velikost
-		delete(fc.cache, path)
-		fc.currentSize -= int64(len(data))
-
-		// Odeber klíč z pořadí
-		for i, key := range fc.order {
-			if key == path {
-				fc.order = append(fc.order[:i], fc.order[i+1:]...)
-				break
-			}
-		}
-		return true
+	if _, exists := fc.cache[path]; !exists {
+		return false
 	}
-	return false
-}
-
-// Soukromá funkce pro odstranění nejstaršího záznamu (používá se při překročení limitu)
-func (fc *FileCache) removeOldest() {
-	if len(fc.order) > 0 {
-		oldestKey := fc.order[0]
-		if data, exists := fc.cache[oldestKey]; exists {
-			delete(fc.cache, oldestKey)
-			fc.currentSize -= int64(len(data))
-			fc.order = fc.order[1:] // Odeber nejstarší klíč
-		}
+	fc.remove(path)
+	return true
+}
+
+func (fc *FileCache) remove(path string) {
+	if fc.cache[path].pinned {
+		fc.pinned--
+	}
+	fc.currentSize -= int64(len(fc.cache[path].data))
+	delete(fc.cache, path)
+	fc.policy.Remove(path)
+}
+
+// Soukromá funkce pro odstranění jednoho záznamu podle strategie (používá se při překročení limitu)
+// Vrací false, pokud už není co odstranit
+func (fc *FileCache) evictOne() bool {
+	victim, ok := fc.policy.Victim()
+	if !ok {
+		return false
 	}
+	fc.remove(victim)
+	fc.evictions++
+	return true
+}
+
+// Funkce pro připnutí souboru, který se pak nikdy neodstraní kvůli místu (např. textura hráče)
+// Vrací false, pokud soubor v cache není
+func (fc *FileCache) Pin(path string) bool {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	e, exists := fc.cache[path]
+	if !exists {
+		return false
+	}
+	if !e.pinned {
+		e.pinned = true
+		fc.pinned++
+		fc.policy.Remove(path)
+	}
+	return true
+}
+
+// Funkce pro uvolnění připnutého souboru; strategie ho bere jako nově přidaný
+func (fc *FileCache) Unpin(path string) bool {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	e, exists := fc.cache[path]
+	if !exists {
+		return false
+	}
+	if e.pinned {
+		e.pinned = false
+		fc.pinned--
+		fc.policy.Add(path)
+	}
+	return true
+}
+
+// Funkce pro získání statistik cache
+func (fc *FileCache) Stats() CacheStats {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return CacheStats{
+		Hits:      fc.hits,
+		Misses:    fc.misses,
+		Evictions: fc.evictions,
+		Entries:   len(fc.cache),
+		Pinned:    fc.pinned,
+		Size:      fc.currentSize,
+		MaxSize:   fc.maxSize,
+	}
+}
+
+// Funkce pro vynulování počítadel zásahů, minutí a odstranění
+func (fc *FileCache) ResetStats() {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.hits, fc.misses, fc.evictions = 0, 0, 0
 }
 
 // Funkce pro vyčištění celé cache
@@ -104,8 +198,9 @@ func (fc *FileCache) ClearCache() {
 	fc.mu.Lock()
 	defer fc.mu.Unlock()
 
-	// Vyprázdni mapu cache a seznam klíčů
-	fc.cache = make(map[string][]byte)
-	fc.order = []string{}
+	// Vyprázdni mapu cache a strategii
+	fc.cache = make(map[string]*cacheEntry)
+	fc.policy.Clear()
 	fc.currentSize = 0
+	fc.pinned = 0
 }
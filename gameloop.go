@@ -1 +1,279 @@
 package main
+
+import (
+	"errors"
+	"io"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Výchozí počet ticků simulace za sekundu
+const DefaultTickRate = 60
+
+// Herní smyčka s pevným krokem simulace
+// Vykreslování běží tak rychle, jak to jde, ale scéna se vyhodnocuje vždy po celých tickách,
+// takže se simulace chová stejně nezávisle na výkonu stroje
+type GameLoop struct {
+	Scene        *Scene
+	Input        *Input
+	TickRate     int
+	MaxFrameTime float64 // maximální doba snímku v sekundách, ochrana proti "spirále smrti"
+	Tick         uint64  // číslo příštího ticku
+	Rewind       *Rewinder
+	Paused       bool // pozastavená simulace (např. při scrubování), vykresluje se dál
+	// Vykreslovat stav mezi posledními dvěma ticky podle zbytku času ve smyčce
+	// Pohyb je plynulý i při frekvenci obrazovky jiné než TickRate, za cenu zpoždění o jeden tick
+	Interpolate bool
+
+	previous map[*Sprite]renderState // stav spritů před posledním tickem
+	current  map[*Sprite]renderState // skutečný stav během interpolovaného vykreslení
+	recorder *Recorder
+	replay   *Replay
+	running  bool
+}
+
+func NewGameLoop(scene *Scene, input *Input) *GameLoop {
+	return &GameLoop{
+		Scene:        scene,
+		Input:        input,
+		TickRate:     DefaultTickRate,
+		MaxFrameTime: 0.25,
+	}
+}
+
+// Délka jednoho ticku v sekundách
+func (g *GameLoop) Step() float64 {
+	return 1 / float64(g.TickRate)
+}
+
+// Funkce pro spuštění nahrávání vstupů
+// Scéna dostane nový seed a počítání ticků začne od nuly, aby šlo session přehrát od začátku
+func (g *GameLoop) StartRecording(w io.Writer, seed uint64) error {
+	rec, err := NewRecorder(w, seed, g.TickRate)
+	if err != nil {
+		return err
+	}
+
+	g.Scene.Reseed(seed)
+	g.Tick = 0
+	g.recorder = rec
+	g.Input.OnEvent = func(ev InputEvent) {
+		g.recorder.Record(g.Tick, ev)
+	}
+	return nil
+}
+
+func (g *GameLoop) StopRecording() error {
+	if g.recorder == nil {
+		return nil
+	}
+	err := g.recorder.Close(g.Tick)
+	g.recorder = nil
+	g.Input.OnEvent = nil
+	return err
+}
+
+// Funkce pro přehrání nahrávky; během přehrávání se ignoruje živý vstup
+// Scéna musí být ve stejném počátečním stavu jako při nahrávání
+func (g *GameLoop) StartReplay(rp *Replay) {
+	rp.Rewind()
+	g.Input.Reset()
+	g.Scene.Reseed(rp.Seed)
+	g.TickRate = rp.TickRate
+	g.Tick = 0
+	g.replay = rp
+}
+
+func (g *GameLoop) Replaying() bool {
+	return g.replay != nil
+}
+
+// Funkce pro provedení jednoho ticku simulace
+func (g *GameLoop) Update() error {
+	if g.Interpolate {
+		g.capturePrevious()
+	}
+	if g.Rewind != nil {
+		g.Rewind.Capture(g.Tick, g.Scene, g.Input)
+	}
+
+	if g.replay != nil {
+		for _, ev := range g.replay.EventsFor(g.Tick) {
+			g.Input.Inject(ev.InputEvent)
+		}
+	}
+
+	if err := g.Scene.Evaluate(); err != nil {
+		return err
+	}
+	if g.Scene.Physics != nil {
+		g.Scene.Physics.Step(g.Scene, g.Step())
+	}
+
+	g.Input.EndTick()
+	g.Tick++
+
+	// Po konci nahrávky se vrátíme k živému vstupu; přehrané hodnoty akcí neodpovídají
+	// podrženým klávesám (živé události se během přehrávání nezpracovávaly), proto se vstup vynuluje
+	if g.replay != nil && g.replay.Done(g.Tick) {
+		g.replay = nil
+		g.Input.Reset()
+	}
+	return nil
+}
+
+// Funkce pro přetočení simulace na nejbližší snapshot, který není novější než tick
+// Simulace zůstane pozastavená, dokud se nezavolá Resume
+func (g *GameLoop) RewindTo(tick uint64) error {
+	if g.Rewind == nil {
+		return errors.New("rewind is not enabled")
+	}
+	if g.recorder != nil {
+		return errors.New("cannot rewind while recording")
+	}
+
+	// Při přehrávání se obnoví i hodnoty akcí, protože Seek přeskočí události před tickem
+	// a klávesy podržené v tomto ticku by se jinak ztratily; živý vstup se přepočte ze zařízení
+	var input *Input
+	if g.replay != nil {
+		input = g.Input
+	}
+	t, err := g.Rewind.Restore(tick, g.Scene, input)
+	if err != nil {
+		return err
+	}
+	g.Tick = t
+	g.Paused = true
+	clear(g.previous) // přetočení je skok, mezi kterým se interpolovat nemá
+	if g.replay != nil {
+		g.replay.Seek(t)
+	} else {
+		g.Input.Resync()
+	}
+	return nil
+}
+
+// Funkce pro posun o daný počet snapshotů dopředu (kladné) nebo dozadu (záporné)
+func (g *GameLoop) Scrub(steps int) error {
+	if g.Rewind == nil {
+		return errors.New("rewind is not enabled")
+	}
+
+	target := int64(g.Tick) + int64(steps)*int64(g.Rewind.Interval)
+	if target < 0 {
+		target = 0
+	}
+	return g.RewindTo(uint64(target))
+}
+
+// Funkce pro pokračování simulace z aktuálního (případně přetočeného) stavu
+// Novější historie se zahodí, protože se od této chvíle může vyvíjet jinak
+func (g *GameLoop) Resume() {
+	if g.Rewind != nil {
+		g.Rewind.DiscardAfter(g.Tick)
+	}
+	g.Paused = false
+}
+
+func (g *GameLoop) Stop() {
+	g.running = false
+}
+
+// Hlavní smyčka: zpracování událostí, pevné kroky simulace a vykreslení
+// Nahrávání se ukončí (a zapíše) při každém ukončení smyčky, i při chybě
+func (g *GameLoop) Run() (err error) {
+	defer func() {
+		err = errors.Join(err, g.StopRecording())
+	}()
+
+	freq := float64(sdl.GetPerformanceFrequency())
+	last := sdl.GetPerformanceCounter()
+	accumulator := 0.0
+
+	g.running = true
+	for g.running {
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			if _, ok := event.(*sdl.QuitEvent); ok {
+				g.running = false
+				continue
+			}
+			if g.replay == nil {
+				g.Input.HandleEvent(event)
+			}
+		}
+
+		now := sdl.GetPerformanceCounter()
+		frame := float64(now-last) / freq
+		last = now
+		if frame > g.MaxFrameTime {
+			frame = g.MaxFrameTime
+		}
+
+		if g.Paused {
+			frame = 0
+			accumulator = 0
+		}
+
+		accumulator += frame
+		for accumulator >= g.Step() {
+			if err := g.Update(); err != nil {
+				return err
+			}
+			accumulator -= g.Step()
+		}
+
+		if err := g.draw(accumulator / g.Step()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Vykreslovaná část stavu spritu
+type renderState struct {
+	Rect   Rectangle
+	Matrix Affine2D
+}
+
+func (g *GameLoop) capturePrevious() {
+	if g.previous == nil {
+		g.previous = make(map[*Sprite]renderState)
+	}
+	clear(g.previous)
+	for _, l := range g.Scene.IterateLayersInOrder() {
+		for _, sp := range l.Sprites {
+			s := sp.GetSprite()
+			g.previous[s] = renderState{Rect: s.Rect, Matrix: s.Matrix}
+		}
+	}
+}
+
+// Vykreslení scény; s Interpolate se sprity dočasně posunou mezi předchozí a aktuální tick
+// (alpha 0 = předchozí tick, 1 = aktuální) a po vykreslení se jim vrátí skutečný stav
+func (g *GameLoop) draw(alpha float64) error {
+	if !g.Interpolate || g.Paused || len(g.previous) == 0 {
+		return g.Scene.Draw()
+	}
+
+	if g.current == nil {
+		g.current = make(map[*Sprite]renderState, len(g.previous))
+	}
+	clear(g.current)
+	for s, prev := range g.previous {
+		g.current[s] = renderState{Rect: s.Rect, Matrix: s.Matrix}
+		s.Rect = Rectangle{
+			Min: lerpPoint(prev.Rect.Min, s.Rect.Min, alpha),
+			Max: lerpPoint(prev.Rect.Max, s.Rect.Max, alpha),
+		}
+		// Při zrcadlení (otočení postavy) se matice neinterpoluje, sprite se kreslí rovnou v novém stavu
+		if !orientationFlips(prev.Matrix, s.Matrix) {
+			s.Matrix = InterpolateAffine(prev.Matrix, s.Matrix, alpha)
+		}
+	}
+	err := g.Scene.Draw()
+	for s, cur := range g.current {
+		s.Rect, s.Matrix = cur.Rect, cur.Matrix
+	}
+	return err
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"maps"
+	"runtime"
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Abstraktní herní akce (skok, pohyb, ...), na kterou se mapuje klávesnice i gamepad
+type Action int
+
+// Výchozí mrtvá zóna analogových os gamepadu (v normalizovaném rozsahu 0..1)
+const DefaultDeadZone = 0.15
+
+// Vazba digitálního vstupu (klávesa, tlačítko) na akci s danou hodnotou
+// Např. šipka vlevo -> MoveX s hodnotou -1, šipka vpravo -> MoveX s hodnotou 1
+type Binding struct {
+	Action Action
+	Value  float64
+}
+
+// Mapování fyzických vstupů na akce
+type InputMap struct {
+	Keys    map[sdl.Keycode]Binding
+	Buttons map[sdl.GameControllerButton]Binding
+	Axes    map[sdl.GameControllerAxis]Action // hodnota osy se předává se znaménkem
+}
+
+func NewInputMap() InputMap {
+	return InputMap{
+		Keys:    make(map[sdl.Keycode]Binding),
+		Buttons: make(map[sdl.GameControllerButton]Binding),
+		Axes:    make(map[sdl.GameControllerAxis]Action),
+	}
+}
+
+func (m *InputMap) BindKey(key sdl.Keycode, a Action, value float64) {
+	m.Keys[key] = Binding{Action: a, Value: value}
+}
+
+func (m *InputMap) BindButton(b sdl.GameControllerButton, a Action, value float64) {
+	m.Buttons[b] = Binding{Action: a, Value: value}
+}
+
+func (m *InputMap) BindAxis(axis sdl.GameControllerAxis, a Action) {
+	m.Axes[axis] = a
+}
+
+// Změna hodnoty akce - výsledek zpracování jedné vstupní události
+type InputEvent struct {
+	Action Action
+	Value  float64
+}
+
+// Druh fyzického zdroje vstupu
+const (
+	sourceKey = iota
+	sourceButton
+	sourceAxis
+)
+
+// Jeden fyzický zdroj (klávesa, nebo tlačítko/osa konkrétního gamepadu)
+type inputSource struct {
+	kind   int
+	device sdl.JoystickID
+	code   int
+}
+
+type sourceValue struct {
+	action Action
+	value  float64
+}
+
+// Stav vstupů hry, sjednocený přes klávesnici a všechny připojené gamepady
+type Input struct {
+	Map      InputMap
+	DeadZone float64
+	OnEvent  func(InputEvent) // volá se při každé změně hodnoty akce (např. pro nahrávání)
+
+	controllers map[sdl.JoystickID]*sdl.GameController
+	sources     map[inputSource]sourceValue // aktuální příspěvek každého zdroje
+	values      map[Action]float64
+	previous    map[Action]float64 // hodnoty z minulého ticku (pro JustPressed/JustReleased)
+}
+
+func NewInput(m InputMap) *Input {
+	return &Input{
+		Map:         m,
+		DeadZone:    DefaultDeadZone,
+		controllers: make(map[sdl.JoystickID]*sdl.GameController),
+		sources:     make(map[inputSource]sourceValue),
+		values:      make(map[Action]float64),
+		previous:    make(map[Action]float64),
+	}
+}
+
+// Názvy platforem tak, jak je používá SDL_GameControllerDB
+var controllerPlatforms = map[string]string{
+	"linux":   "Linux",
+	"windows": "Windows",
+	"darwin":  "Mac OS X",
+	"android": "Android",
+	"ios":     "iOS",
+}
+
+// Funkce pro načtení databáze mapování gamepadů (gamecontrollerdb.txt) přes FileCache
+// Vrací počet přidaných mapování
+func (in *Input) LoadMappings(cache *FileCache, path string) (int, error) {
+	data, err := cache.LoadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	platform := "platform:" + controllerPlatforms[runtime.GOOS] + ","
+	added := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Stejně jako SDL_GameControllerAddMappingsFromRW přeskočíme mapování pro jiné platformy
+		if strings.Contains(line, "platform:") && !strings.Contains(line, platform) {
+			continue
+		}
+		if sdl.GameControllerAddMapping(line) < 0 {
+			return added, fmt.Errorf("invalid controller mapping: %v", sdl.GetError())
+		}
+		added++
+	}
+	if err := scanner.Err(); err != nil {
+		return added, err
+	}
+
+	return added, nil
+}
+
+// Funkce pro zpracování SDL události; vrací true, pokud šlo o vstupní událost
+func (in *Input) HandleEvent(event sdl.Event) bool {
+	switch e := event.(type) {
+	case *sdl.KeyboardEvent:
+		if e.Repeat != 0 {
+			return true
+		}
+		b, ok := in.Map.Keys[e.Keysym.Sym]
+		if !ok {
+			return true
+		}
+		in.setSource(inputSource{kind: sourceKey, code: int(e.Keysym.Sym)}, b.Action, pressedValue(e.State, b.Value))
+
+	case *sdl.ControllerButtonEvent:
+		b, ok := in.Map.Buttons[sdl.GameControllerButton(e.Button)]
+		if !ok {
+			return true
+		}
+		in.setSource(inputSource{kind: sourceButton, device: e.Which, code: int(e.Button)}, b.Action, pressedValue(e.State, b.Value))
+
+	case *sdl.ControllerAxisEvent:
+		a, ok := in.Map.Axes[sdl.GameControllerAxis(e.Axis)]
+		if !ok {
+			return true
+		}
+		in.setSource(inputSource{kind: sourceAxis, device: e.Which, code: int(e.Axis)}, a, in.normalizeAxis(e.Value))
+
+	case *sdl.ControllerDeviceEvent:
+		switch e.Type {
+		case sdl.CONTROLLERDEVICEADDED:
+			in.openController(int(e.Which))
+		case sdl.CONTROLLERDEVICEREMOVED:
+			in.closeController(e.Which)
+		}
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// Při CONTROLLERDEVICEADDED je Which index zařízení, ne instance id
+func (in *Input) openController(index int) {
+	if !sdl.IsGameController(index) {
+		return
+	}
+	c := sdl.GameControllerOpen(index)
+	if c == nil {
+		return
+	}
+	in.controllers[c.Joystick().InstanceID()] = c
+}
+
+func (in *Input) closeController(id sdl.JoystickID) {
+	c, exists := in.controllers[id]
+	if !exists {
+		return
+	}
+	c.Close()
+	delete(in.controllers, id)
+
+	// Uvolníme všechna tlačítka a osy odpojeného gamepadu
+	for src, sv := range in.sources {
+		if src.kind != sourceKey && src.device == id {
+			in.setSource(src, sv.action, 0)
+		}
+	}
+}
+
+// Počet právě připojených gamepadů
+func (in *Input) Controllers() int {
+	return len(in.controllers)
+}
+
+// Funkce pro zavření všech otevřených gamepadů
+func (in *Input) Close() {
+	for id := range in.controllers {
+		in.closeController(id)
+	}
+}
+
+func pressedValue(state uint8, value float64) float64 {
+	if state == sdl.PRESSED {
+		return value
+	}
+	return 0
+}
+
+// Převod hodnoty osy na rozsah -1..1 s mrtvou zónou; mimo ni se rozsah znovu roztáhne,
+// aby hodnota plynule navazovala na nulu
+func (in *Input) normalizeAxis(raw int16) float64 {
+	v := float64(raw) / 32767
+	if v < -1 {
+		v = -1
+	}
+
+	sign := 1.0
+	if v < 0 {
+		sign, v = -1, -v
+	}
+	if v <= in.DeadZone {
+		return 0
+	}
+	if in.DeadZone >= 1 {
+		return sign
+	}
+	return sign * (v - in.DeadZone) / (1 - in.DeadZone)
+}
+
+// Nastaví příspěvek zdroje k akci a přepočítá její hodnotu
+func (in *Input) setSource(src inputSource, a Action, value float64) {
+	old := in.sources[src]
+	if old.value == value {
+		return
+	}
+	if value == 0 {
+		delete(in.sources, src)
+	} else {
+		in.sources[src] = sourceValue{action: a, value: value}
+	}
+
+	total := in.values[a] - old.value + value
+	if total > -1e-9 && total < 1e-9 {
+		total = 0
+	}
+	in.apply(InputEvent{Action: a, Value: total})
+}
+
+// Funkce pro přímé nastavení hodnoty akce, bez fyzického vstupu (např. při přehrávání replaye)
+func (in *Input) Inject(ev InputEvent) {
+	in.apply(ev)
+}
+
+func (in *Input) apply(ev InputEvent) {
+	if in.OnEvent != nil {
+		in.OnEvent(ev)
+	}
+	if ev.Value == 0 {
+		delete(in.values, ev.Action)
+		return
+	}
+	in.values[ev.Action] = ev.Value
+}
+
+// Hodnota akce omezená na rozsah -1..1 (více zdrojů se sčítá)
+func (in *Input) Value(a Action) float64 {
+	v := in.values[a]
+	if v > 1 {
+		return 1
+	}
+	if v < -1 {
+		return -1
+	}
+	return v
+}
+
+func (in *Input) Pressed(a Action) bool {
+	return in.values[a] != 0
+}
+
+func (in *Input) JustPressed(a Action) bool {
+	return in.values[a] != 0 && in.previous[a] == 0
+}
+
+func (in *Input) JustReleased(a Action) bool {
+	return in.values[a] == 0 && in.previous[a] != 0
+}
+
+// Uložený stav akcí (pro snapshoty při přetáčení)
+type InputState struct {
+	values   map[Action]float64
+	previous map[Action]float64
+}
+
+func (in *Input) SaveState() InputState {
+	return InputState{values: maps.Clone(in.values), previous: maps.Clone(in.previous)}
+}
+
+// Funkce pro obnovení hodnot akcí ze snapshotu; příspěvky fyzických zdrojů se zahodí,
+// protože už neodpovídají obnoveným hodnotám
+func (in *Input) LoadState(st InputState) {
+	clear(in.sources)
+	clear(in.values)
+	clear(in.previous)
+	maps.Copy(in.values, st.values)
+	maps.Copy(in.previous, st.previous)
+}
+
+// Funkce pro přepočet hodnot akcí z aktuálně podržených fyzických vstupů
+// (např. po přetočení, aby hodnoty akcí odpovídaly zařízením a ne uloženému stavu)
+func (in *Input) Resync() {
+	clear(in.values)
+	for _, sv := range in.sources {
+		in.values[sv.action] += sv.value
+	}
+	for a, v := range in.values {
+		if v > -1e-9 && v < 1e-9 {
+			delete(in.values, a)
+		}
+	}
+	clear(in.previous)
+	maps.Copy(in.previous, in.values)
+}
+
+// Funkce pro vynulování všech akcí a zdrojů (např. po konci přehrávání, kdy se živý vstup nesledoval)
+// Podržené klávesy se projeví až při dalším stisku
+func (in *Input) Reset() {
+	clear(in.sources)
+	clear(in.values)
+	clear(in.previous)
+}
+
+// Funkce volaná na konci každého ticku - zapamatuje si stav pro JustPressed/JustReleased
+func (in *Input) EndTick() {
+	for a := range in.previous {
+		delete(in.previous, a)
+	}
+	for a, v := range in.values {
+		in.previous[a] = v
+	}
+}
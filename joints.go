@@ -0,0 +1,299 @@
+package main
+
+import "math"
+
+// Omezení mezi dvěma sprity nebo mezi spritem a pevným bodem ve světě
+// Pokud je některý ze spritů nil, jeho kotva je bod ve světových souřadnicích
+type Joint interface {
+	ApplyForces(dt float64) // síly před integrací (pružiny)
+	SolveVelocity()         // jedna iterace řešiče rychlostí
+	SolvePosition()         // korekce polohy po vyřešení rychlostí
+}
+
+// Kotva spojení: bod relativně ke středu spritu, nebo bod ve světě pro s == nil
+type jointEnd struct {
+	s      *Sprite
+	anchor Vector
+}
+
+func (e jointEnd) point() Point {
+	if e.s == nil {
+		return Point{X: e.anchor.X, Y: e.anchor.Y}
+	}
+	return e.s.Rect.Center().AddVector(e.anchor)
+}
+
+func (e jointEnd) velocity() Vector {
+	if e.s == nil {
+		return Vector{}
+	}
+	return e.s.Movement
+}
+
+func (e jointEnd) invMass() float64 {
+	if e.s == nil {
+		return 0
+	}
+	return e.s.Body.InvMass()
+}
+
+func (e jointEnd) impulse(j Vector) {
+	if e.s != nil {
+		e.s.ApplyImpulse(j)
+	}
+}
+
+func (e jointEnd) move(d Vector) {
+	if e.s != nil && e.s.Body.Mode == Dynamic {
+		e.s.Translate(d.Scale(e.s.Body.InvMass()))
+	}
+}
+
+// Impulz ve směru n, který vynuluje relativní rychlost b vůči a ve směru n
+// clamp může impulz omezit (např. lano jen táhne), nil = bez omezení
+func solveAxis(a, b jointEnd, n Vector, clamp func(float64) float64) {
+	k := a.invMass() + b.invMass()
+	if k == 0 {
+		return
+	}
+	vr := b.velocity().Add(a.velocity().Scale(-1))
+	lambda := -vr.Dot(n) / k
+	if clamp != nil {
+		lambda = clamp(lambda)
+	}
+	a.impulse(n.Scale(-lambda))
+	b.impulse(n.Scale(lambda))
+}
+
+// Posun obou konců ve směru n tak, aby se chyba polohy c (kladná = příliš daleko) zmenšila
+func correctAxis(a, b jointEnd, n Vector, c float64) {
+	k := a.invMass() + b.invMass()
+	if k == 0 {
+		return
+	}
+	d := n.Scale(c * positionCorrection / k)
+	a.move(d)
+	b.move(d.Scale(-1))
+}
+
+// Pevná vzdálenost mezi dvěma body
+type DistanceJoint struct {
+	A, B             *Sprite
+	AnchorA, AnchorB Vector
+	Length           float64
+}
+
+func NewDistanceJoint(a, b *Sprite, anchorA, anchorB Vector) *DistanceJoint {
+	j := &DistanceJoint{A: a, B: b, AnchorA: anchorA, AnchorB: anchorB}
+	j.Length = subPoints(j.ends()[1].point(), j.ends()[0].point()).Length()
+	return j
+}
+
+func (j *DistanceJoint) ends() [2]jointEnd {
+	return [2]jointEnd{{j.A, j.AnchorA}, {j.B, j.AnchorB}}
+}
+
+func (j *DistanceJoint) ApplyForces(dt float64) {}
+
+func (j *DistanceJoint) SolveVelocity() {
+	e := j.ends()
+	n := subPoints(e[1].point(), e[0].point()).Normalize()
+	if n.Length() == 0 {
+		return
+	}
+	solveAxis(e[0], e[1], n, nil)
+}
+
+func (j *DistanceJoint) SolvePosition() {
+	e := j.ends()
+	d := subPoints(e[1].point(), e[0].point())
+	if l := d.Length(); l > 0 {
+		correctAxis(e[0], e[1], d.Scale(1/l), l-j.Length)
+	}
+}
+
+// Lano: omezuje jen maximální vzdálenost, při menší je volné
+type RopeJoint struct {
+	A, B             *Sprite
+	AnchorA, AnchorB Vector
+	MaxLength        float64
+}
+
+func (j *RopeJoint) ends() [2]jointEnd {
+	return [2]jointEnd{{j.A, j.AnchorA}, {j.B, j.AnchorB}}
+}
+
+func (j *RopeJoint) ApplyForces(dt float64) {}
+
+func (j *RopeJoint) SolveVelocity() {
+	e := j.ends()
+	d := subPoints(e[1].point(), e[0].point())
+	if d.Length() < j.MaxLength {
+		return
+	}
+	// Napnuté lano může konce jen přitahovat
+	solveAxis(e[0], e[1], d.Normalize(), func(l float64) float64 { return math.Min(l, 0) })
+}
+
+func (j *RopeJoint) SolvePosition() {
+	e := j.ends()
+	d := subPoints(e[1].point(), e[0].point())
+	if l := d.Length(); l > j.MaxLength {
+		correctAxis(e[0], e[1], d.Scale(1/l), l-j.MaxLength)
+	}
+}
+
+// Tlumená pružina (Hookeův zákon s tlumením)
+type SpringJoint struct {
+	A, B             *Sprite
+	AnchorA, AnchorB Vector
+	RestLength       float64
+	Stiffness        float64 // tuhost pružiny
+	Damping          float64 // tlumení relativní rychlosti ve směru pružiny
+}
+
+func (j *SpringJoint) ApplyForces(dt float64) {
+	a, b := jointEnd{j.A, j.AnchorA}, jointEnd{j.B, j.AnchorB}
+	d := subPoints(b.point(), a.point())
+	l := d.Length()
+	if l == 0 {
+		return
+	}
+	n := d.Scale(1 / l)
+	vr := b.velocity().Add(a.velocity().Scale(-1))
+
+	f := n.Scale(-j.Stiffness*(l-j.RestLength) - j.Damping*vr.Dot(n))
+	if j.A != nil {
+		j.A.Body.ApplyForce(f.Scale(-1))
+	}
+	if j.B != nil {
+		j.B.Body.ApplyForce(f)
+	}
+}
+
+func (j *SpringJoint) SolveVelocity() {}
+
+func (j *SpringJoint) SolvePosition() {}
+
+// Připíchnutí bodu spritu k pevnému bodu ve světě
+type PinJoint struct {
+	Sprite *Sprite
+	Anchor Vector // bod relativně ke středu spritu
+	Target Point  // bod ve světě
+}
+
+func (j *PinJoint) ends() [2]jointEnd {
+	return [2]jointEnd{{nil, Vector{X: j.Target.X, Y: j.Target.Y}}, {j.Sprite, j.Anchor}}
+}
+
+func (j *PinJoint) ApplyForces(dt float64) {}
+
+func (j *PinJoint) SolveVelocity() {
+	e := j.ends()
+	solveAxis(e[0], e[1], Vector{X: 1}, nil)
+	solveAxis(e[0], e[1], Vector{Y: 1}, nil)
+}
+
+func (j *PinJoint) SolvePosition() {
+	e := j.ends()
+	d := subPoints(e[1].point(), e[0].point())
+	correctAxis(e[0], e[1], Vector{X: 1}, d.X)
+	correctAxis(e[0], e[1], Vector{Y: 1}, d.Y)
+}
+
+// Kloub: kotva B se drží v bodě kotvy A (pivot) a B se kolem něj otáčí
+// Protože těla nemají vlastní rotaci, kotva B se otáčí spolu s ramenem od pivotu ke středu B;
+// střed B se tak pohybuje po kružnici s poloměrem |AnchorB|
+// Úhel ramene lze omezit; s Rotate se podle úhlu natáčí i matice B
+type RevoluteJoint struct {
+	A, B             *Sprite
+	AnchorA, AnchorB Vector
+	EnableLimit      bool
+	Lower, Upper     float64 // meze úhlu v radiánech vůči výchozímu úhlu
+	Rotate           bool
+
+	refAngle float64
+	started  bool
+}
+
+func (j *RevoluteJoint) ends() [2]jointEnd {
+	// Druhý konec je střed B, vazba drží jeho vzdálenost od pivotu
+	return [2]jointEnd{{j.A, j.AnchorA}, {j.B, Vector{}}}
+}
+
+// Aktuální úhel ramene B vůči výchozímu stavu
+func (j *RevoluteJoint) Angle() float64 {
+	if j.B == nil {
+		return 0
+	}
+	arm := subPoints(j.B.Rect.Center(), j.ends()[0].point())
+	a := math.Atan2(arm.Y, arm.X)
+	if !j.started {
+		j.refAngle = a
+		j.started = true
+	}
+	return math.Remainder(a-j.refAngle, 2*math.Pi)
+}
+
+func (j *RevoluteJoint) ApplyForces(dt float64) {
+	j.Angle() // zapamatuje si výchozí úhel
+}
+
+func (j *RevoluteJoint) SolveVelocity() {
+	if j.B == nil {
+		return
+	}
+	e := j.ends()
+	arm := subPoints(e[1].point(), e[0].point())
+	if j.AnchorB.Length() == 0 || arm.Length() == 0 {
+		// Kotva ve středu B - kloub se chová jako připíchnutí
+		solveAxis(e[0], e[1], Vector{X: 1}, nil)
+		solveAxis(e[0], e[1], Vector{Y: 1}, nil)
+		return
+	}
+	solveAxis(e[0], e[1], arm.Normalize(), nil)
+
+	if !j.EnableLimit {
+		return
+	}
+	// Na mezi zastavíme tečnou rychlost, která by úhel dál zvětšovala
+	t := arm.Perpendicular().Normalize()
+	angle := j.Angle()
+	vt := e[1].velocity().Add(e[0].velocity().Scale(-1)).Dot(t)
+	if (angle <= j.Lower && vt < 0) || (angle >= j.Upper && vt > 0) {
+		solveAxis(e[0], e[1], t, nil)
+	}
+}
+
+func (j *RevoluteJoint) SolvePosition() {
+	if j.B == nil {
+		return
+	}
+	e := j.ends()
+	arm := subPoints(e[1].point(), e[0].point())
+	radius := j.AnchorB.Length()
+	if l := arm.Length(); l > 0 {
+		correctAxis(e[0], e[1], arm.Scale(1/l), l-radius)
+	} else if radius > 0 {
+		return
+	}
+
+	angle := j.Angle()
+	if j.EnableLimit && (angle < j.Lower || angle > j.Upper) && j.B.Body.Mode == Dynamic {
+		// Vrácení ramene na mez otočením středu B kolem pivotu
+		pivot := e[0].point()
+		arm = subPoints(j.B.Rect.Center(), pivot)
+		target := math.Max(j.Lower, math.Min(angle, j.Upper))
+		rot := target - angle
+		cos, sin := math.Cos(rot), math.Sin(rot)
+		turned := Vector{X: arm.X*cos - arm.Y*sin, Y: arm.X*sin + arm.Y*cos}
+		j.B.Translate(turned.Add(arm.Scale(-1)))
+		angle = target
+	}
+
+	if j.Rotate {
+		// Otočení spritu kolem vlastního středu
+		c := j.B.Rect.Center()
+		j.B.Matrix = RotationAroundAffine(c, angle)
+	}
+}
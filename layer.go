@@ -84,7 +84,15 @@ func (l *Layer) Evaluate() error {
 }
 
 func (l *Layer) Draw(r *sdl.Renderer) error {
+	return l.DrawVisible(r, nil)
+}
+
+// Vykreslí jen sprity, pro které visible vrátí true (nil = všechny)
+func (l *Layer) DrawVisible(r *sdl.Renderer, visible func(Spriter) bool) error {
 	for _, s := range l.Sprites {
+		if visible != nil && !visible(s) {
+			continue
+		}
 		s.Draw(r)
 	}
 
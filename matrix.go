@@ -0,0 +1,379 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Relativní tolerance pro rozpoznání singulární matice
+const MatrixEpsilon = 1e-12
+
+var ErrSingularMatrix = errors.New("matice nemá inverzi")
+
+func dimensionError(op string, m1, m2 *Matrix) error {
+	return fmt.Errorf("%s: rozměry matic %dx%d a %dx%d nesouhlasí", op, m1.Rows, m1.Columns, m2.Rows, m2.Columns)
+}
+
+func (m *Matrix) isSize(rows, columns int) bool {
+	return m.Rows == rows && m.Columns == columns
+}
+
+// Metoda pro sčítání dvou matic s kontrolou rozměrů
+func (m1 *Matrix) AddChecked(m2 *Matrix) (*Matrix, error) {
+	if !m1.isSize(m2.Rows, m2.Columns) {
+		return nil, dimensionError("sčítání", m1, m2)
+	}
+	result := NewMatrix(m1.Rows, m1.Columns)
+	for i := 0; i < m1.Rows; i++ {
+		for j := 0; j < m1.Columns; j++ {
+			result.Data[i][j] = m1.Data[i][j] + m2.Data[i][j]
+		}
+	}
+	return result, nil
+}
+
+// Metoda pro odčítání dvou matic s kontrolou rozměrů
+func (m1 *Matrix) SubtractChecked(m2 *Matrix) (*Matrix, error) {
+	if !m1.isSize(m2.Rows, m2.Columns) {
+		return nil, dimensionError("odčítání", m1, m2)
+	}
+	result := NewMatrix(m1.Rows, m1.Columns)
+	for i := 0; i < m1.Rows; i++ {
+		for j := 0; j < m1.Columns; j++ {
+			result.Data[i][j] = m1.Data[i][j] - m2.Data[i][j]
+		}
+	}
+	return result, nil
+}
+
+// Metoda pro násobení matic s kontrolou rozměrů; matice 3x3 mají rozepsanou rychlou cestu
+func (m1 *Matrix) MultiplyChecked(m2 *Matrix) (*Matrix, error) {
+	if m1.Columns != m2.Rows {
+		return nil, dimensionError("násobení", m1, m2)
+	}
+	if m1.isSize(3, 3) && m2.isSize(3, 3) {
+		return multiply3x3(m1, m2), nil
+	}
+
+	result := NewMatrix(m1.Rows, m2.Columns)
+	for i := 0; i < m1.Rows; i++ {
+		for j := 0; j < m2.Columns; j++ {
+			for k := 0; k < m1.Columns; k++ {
+				result.Data[i][j] += m1.Data[i][k] * m2.Data[k][j]
+			}
+		}
+	}
+	return result, nil
+}
+
+func multiply3x3(m1, m2 *Matrix) *Matrix {
+	a, b := m1.Data, m2.Data
+	result := NewMatrix(3, 3)
+	r := result.Data
+	for i := 0; i < 3; i++ {
+		r[i][0] = a[i][0]*b[0][0] + a[i][1]*b[1][0] + a[i][2]*b[2][0]
+		r[i][1] = a[i][0]*b[0][1] + a[i][1]*b[1][1] + a[i][2]*b[2][1]
+		r[i][2] = a[i][0]*b[0][2] + a[i][1]*b[1][2] + a[i][2]*b[2][2]
+	}
+	return result
+}
+
+// Největší absolutní hodnota prvku (měřítko pro relativní toleranci)
+func (m *Matrix) maxAbs() float64 {
+	max := 0.0
+	for _, row := range m.Data {
+		for _, v := range row {
+			max = math.Max(max, math.Abs(v))
+		}
+	}
+	return max
+}
+
+// Metoda pro výpočet determinantu čtvercové matice
+func (m *Matrix) Determinant() (float64, error) {
+	if m.Rows != m.Columns {
+		return 0, fmt.Errorf("matice musí být čtvercová")
+	}
+	d := m.Data
+	switch m.Rows {
+	case 0:
+		return 1, nil
+	case 1:
+		return d[0][0], nil
+	case 2:
+		return d[0][0]*d[1][1] - d[0][1]*d[1][0], nil
+	case 3:
+		return det3x3(d), nil
+	}
+	lu, err := m.LU()
+	if err != nil {
+		return 0, err
+	}
+	return lu.Determinant(), nil
+}
+
+func det3x3(d [][]float64) float64 {
+	return d[0][0]*(d[1][1]*d[2][2]-d[1][2]*d[2][1]) -
+		d[0][1]*(d[1][0]*d[2][2]-d[1][2]*d[2][0]) +
+		d[0][2]*(d[1][0]*d[2][1]-d[1][1]*d[2][0])
+}
+
+// LU rozklad s částečnou pivotací: P*A = L*U
+// L (s jedničkami na diagonále) a U jsou uložené v jedné matici
+type LUDecomposition struct {
+	lu       *Matrix
+	pivot    []int // pivot[i] = řádek původní matice na i-té pozici
+	sign     float64
+	singular bool
+}
+
+// Metoda pro LU rozklad čtvercové matice
+// Rozklad se provede i pro singulární matici; řešení soustavy pak vrátí ErrSingularMatrix
+func (m *Matrix) LU() (*LUDecomposition, error) {
+	return m.luWithTolerance(MatrixEpsilon)
+}
+
+func (m *Matrix) luWithTolerance(tolerance float64) (*LUDecomposition, error) {
+	if m.Rows != m.Columns {
+		return nil, fmt.Errorf("matice musí být čtvercová")
+	}
+	n := m.Rows
+	d := &LUDecomposition{lu: m.Clone(), pivot: make([]int, n), sign: 1}
+	for i := range d.pivot {
+		d.pivot[i] = i
+	}
+	a := d.lu.Data
+	limit := tolerance * m.maxAbs() * float64(max(n, 1))
+
+	for k := 0; k < n; k++ {
+		// Řádek s největším prvkem ve sloupci k
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(a[i][k]) > math.Abs(a[p][k]) {
+				p = i
+			}
+		}
+		if p != k {
+			a[p], a[k] = a[k], a[p]
+			d.pivot[p], d.pivot[k] = d.pivot[k], d.pivot[p]
+			d.sign = -d.sign
+		}
+
+		if math.Abs(a[k][k]) <= limit {
+			d.singular = true
+			continue
+		}
+		for i := k + 1; i < n; i++ {
+			a[i][k] /= a[k][k]
+			for j := k + 1; j < n; j++ {
+				a[i][j] -= a[i][k] * a[k][j]
+			}
+		}
+	}
+	return d, nil
+}
+
+func (d *LUDecomposition) Singular() bool {
+	return d.singular
+}
+
+// Dolní trojúhelníková matice s jedničkami na diagonále
+func (d *LUDecomposition) L() *Matrix {
+	n := d.lu.Rows
+	l := IdentityMatrix(n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			l.Data[i][j] = d.lu.Data[i][j]
+		}
+	}
+	return l
+}
+
+// Horní trojúhelníková matice
+func (d *LUDecomposition) U() *Matrix {
+	n := d.lu.Rows
+	u := NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			u.Data[i][j] = d.lu.Data[i][j]
+		}
+	}
+	return u
+}
+
+// Permutace řádků: i-tý řádek L*U odpovídá řádku Pivot()[i] původní matice
+func (d *LUDecomposition) Pivot() []int {
+	return append([]int(nil), d.pivot...)
+}
+
+func (d *LUDecomposition) Determinant() float64 {
+	if d.singular {
+		return 0
+	}
+	det := d.sign
+	for i := 0; i < d.lu.Rows; i++ {
+		det *= d.lu.Data[i][i]
+	}
+	return det
+}
+
+// Řešení soustavy A*x = b dopřednou a zpětnou substitucí
+func (d *LUDecomposition) Solve(b []float64) ([]float64, error) {
+	n := d.lu.Rows
+	if len(b) != n {
+		return nil, fmt.Errorf("pravá strana má %d prvků, matice má %d řádků", len(b), n)
+	}
+	if d.singular {
+		return nil, ErrSingularMatrix
+	}
+
+	a := d.lu.Data
+	x := make([]float64, n)
+	for i := 0; i < n; i++ {
+		x[i] = b[d.pivot[i]]
+		for j := 0; j < i; j++ {
+			x[i] -= a[i][j] * x[j]
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := i + 1; j < n; j++ {
+			x[i] -= a[i][j] * x[j]
+		}
+		x[i] /= a[i][i]
+	}
+	return x, nil
+}
+
+// Inverzní matice z rozkladu (řešení soustavy pro každý sloupec jednotkové matice)
+func (d *LUDecomposition) Inverse() (*Matrix, error) {
+	n := d.lu.Rows
+	if d.singular {
+		return nil, ErrSingularMatrix
+	}
+	inverse := NewMatrix(n, n)
+	e := make([]float64, n)
+	for j := 0; j < n; j++ {
+		for i := range e {
+			e[i] = 0
+		}
+		e[j] = 1
+		col, err := d.Solve(e)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			inverse.Data[i][j] = col[i]
+		}
+	}
+	return inverse, nil
+}
+
+// Funkce pro výpočet inverzní matice
+// Matice se považuje za singulární, pokud je pivot menší než MatrixEpsilon vůči největšímu prvku
+// (u afinní 3x3 vůči lineární části, viz singular3x3)
+func (m *Matrix) Inverse() (*Matrix, error) {
+	return m.InverseWithTolerance(MatrixEpsilon)
+}
+
+// Inverzní matice s vlastní relativní tolerancí singularity
+func (m *Matrix) InverseWithTolerance(tolerance float64) (*Matrix, error) {
+	if m.Rows != m.Columns {
+		return nil, fmt.Errorf("matice musí být čtvercová")
+	}
+	if m.Rows == 3 {
+		return inverse3x3(m, tolerance)
+	}
+	lu, err := m.luWithTolerance(tolerance)
+	if err != nil {
+		return nil, err
+	}
+	return lu.Inverse()
+}
+
+// Inverze 3x3 přes adjungovanou matici
+func inverse3x3(m *Matrix, tolerance float64) (*Matrix, error) {
+	d := m.Data
+	det := det3x3(d)
+	if singular3x3(d, det, tolerance) {
+		return nil, ErrSingularMatrix
+	}
+	inv := 1 / det
+
+	result := NewMatrix(3, 3)
+	r := result.Data
+	r[0][0] = (d[1][1]*d[2][2] - d[1][2]*d[2][1]) * inv
+	r[0][1] = (d[0][2]*d[2][1] - d[0][1]*d[2][2]) * inv
+	r[0][2] = (d[0][1]*d[1][2] - d[0][2]*d[1][1]) * inv
+	r[1][0] = (d[1][2]*d[2][0] - d[1][0]*d[2][2]) * inv
+	r[1][1] = (d[0][0]*d[2][2] - d[0][2]*d[2][0]) * inv
+	r[1][2] = (d[0][2]*d[1][0] - d[0][0]*d[1][2]) * inv
+	r[2][0] = (d[1][0]*d[2][1] - d[1][1]*d[2][0]) * inv
+	r[2][1] = (d[0][1]*d[2][0] - d[0][0]*d[2][1]) * inv
+	r[2][2] = (d[0][0]*d[1][1] - d[0][1]*d[1][0]) * inv
+	return result, nil
+}
+
+// Test singularity 3x3 relativně k velikosti prvků
+// U afinní matice (poslední řádek 0 0 1) je determinant roven determinantu lineární části 2x2
+// a porovnává se jen s ní, takže velký posun (světové souřadnice) matici nečiní singulární
+func singular3x3(d [][]float64, det, tolerance float64) bool {
+	if d[2][0] == 0 && d[2][1] == 0 && d[2][2] == 1 {
+		lin := math.Max(math.Max(math.Abs(d[0][0]), math.Abs(d[0][1])), math.Max(math.Abs(d[1][0]), math.Abs(d[1][1])))
+		return lin == 0 || math.Abs(det) <= tolerance*lin*lin*2
+	}
+	scale := 0.0
+	for _, row := range d {
+		for _, v := range row {
+			scale = math.Max(scale, math.Abs(v))
+		}
+	}
+	return scale == 0 || math.Abs(det) <= tolerance*scale*scale*scale
+}
+
+// Funkce pro řešení soustavy lineárních rovnic m*x = b
+func (m *Matrix) Solve(b []float64) ([]float64, error) {
+	lu, err := m.LU()
+	if err != nil {
+		return nil, err
+	}
+	return lu.Solve(b)
+}
+
+// Porovnání matic s tolerancí epsilon pro každý prvek
+func (m1 *Matrix) Equal(m2 *Matrix, epsilon float64) bool {
+	if m1 == nil || m2 == nil {
+		return m1 == m2
+	}
+	if !m1.isSize(m2.Rows, m2.Columns) {
+		return false
+	}
+	for i := 0; i < m1.Rows; i++ {
+		for j := 0; j < m1.Columns; j++ {
+			if math.Abs(m1.Data[i][j]-m2.Data[i][j]) > epsilon {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Textová podoba matice po řádcích
+func (m *Matrix) String() string {
+	var sb strings.Builder
+	for i := 0; i < m.Rows; i++ {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteByte('[')
+		for j := 0; j < m.Columns; j++ {
+			if j > 0 {
+				sb.WriteByte(' ')
+			}
+			fmt.Fprintf(&sb, "%g", m.Data[i][j])
+		}
+		sb.WriteByte(']')
+	}
+	return sb.String()
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// Navigační síť z konvexních průchodných polygonů
+// Sousední polygony musí sdílet celou hranu (stejné koncové body); po vytvoření se síť nemění,
+// takže ji lze bezpečně používat z více goroutin
+type NavMesh struct {
+	Polygons [][]Point
+
+	bounds  []Rectangle
+	centers []Point
+	links   [][]navLink
+}
+
+// Portál mezi dvěma polygony (jejich společná hrana)
+type navLink struct {
+	to          int
+	left, right Point // koncové body portálu při pohledu ve směru průchodu
+}
+
+type navEdgeKey [4]int64
+
+func navEdge(a, b Point) navEdgeKey {
+	q := func(v float64) int64 { return int64(math.Round(v * 1e6)) }
+	k := navEdgeKey{q(a.X), q(a.Y), q(b.X), q(b.Y)}
+	if k[0] > k[2] || (k[0] == k[2] && k[1] > k[3]) {
+		k = navEdgeKey{k[2], k[3], k[0], k[1]}
+	}
+	return k
+}
+
+// Dvojnásobek orientovaného obsahu trojúhelníku abc
+func triarea2(a, b, c Point) float64 {
+	return (c.X-a.X)*(b.Y-a.Y) - (b.X-a.X)*(c.Y-a.Y)
+}
+
+// Funkce pro vytvoření navmeshe z konvexních polygonů
+func NewNavMesh(polygons [][]Point) (*NavMesh, error) {
+	n := &NavMesh{
+		Polygons: polygons,
+		bounds:   make([]Rectangle, len(polygons)),
+		centers:  make([]Point, len(polygons)),
+		links:    make([][]navLink, len(polygons)),
+	}
+
+	type edgeRef struct {
+		poly int
+		a, b Point
+	}
+	edges := make(map[navEdgeKey][]edgeRef)
+
+	for i, poly := range polygons {
+		if len(poly) < 3 {
+			return nil, fmt.Errorf("navmesh polygon %d has fewer than 3 vertices", i)
+		}
+		if !isConvex(poly) {
+			return nil, fmt.Errorf("navmesh polygon %d is not convex", i)
+		}
+		n.bounds[i] = BoundingBox(poly)
+		n.centers[i] = centroidOf(poly)
+
+		for k := range poly {
+			a, b := poly[k], poly[(k+1)%len(poly)]
+			key := navEdge(a, b)
+			edges[key] = append(edges[key], edgeRef{poly: i, a: a, b: b})
+		}
+	}
+
+	for i, poly := range polygons {
+		for k := range poly {
+			for _, e := range edges[navEdge(poly[k], poly[(k+1)%len(poly)])] {
+				if e.poly == i {
+					continue
+				}
+				// Levý a pravý konec portálu určíme vůči středu polygonu, ze kterého se vychází
+				left, right := e.a, e.b
+				if triarea2(n.centers[i], left, right) < 0 {
+					left, right = right, left
+				}
+				n.links[i] = append(n.links[i], navLink{to: e.poly, left: left, right: right})
+			}
+		}
+	}
+	return n, nil
+}
+
+func isConvex(poly []Point) bool {
+	sign := 0.0
+	for i := range poly {
+		a, b, c := poly[i], poly[(i+1)%len(poly)], poly[(i+2)%len(poly)]
+		cross := triarea2(a, b, c)
+		if math.Abs(cross) < collisionEpsilon {
+			continue
+		}
+		if sign == 0 {
+			sign = cross
+		} else if (cross > 0) != (sign > 0) {
+			return false
+		}
+	}
+	return sign != 0
+}
+
+// Funkce vrací index polygonu, který obsahuje bod, nebo -1
+func (n *NavMesh) FindPolygon(p Point) int {
+	for i, poly := range n.Polygons {
+		if n.bounds[i].Contains(p) && pointInConvex(p, poly) {
+			return i
+		}
+	}
+	return -1
+}
+
+func pointInConvex(p Point, poly []Point) bool {
+	sign := 0.0
+	for i := range poly {
+		cross := triarea2(poly[i], poly[(i+1)%len(poly)], p)
+		if math.Abs(cross) < collisionEpsilon {
+			continue // bod leží na hraně
+		}
+		if sign == 0 {
+			sign = cross
+		} else if (cross > 0) != (sign > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// Funkce pro nalezení cesty po navmeshi
+// A* najde koridor polygonů a cesta se jím vyhladí algoritmem trychtýře (string pulling)
+func (n *NavMesh) FindPath(from, to Point) ([]Point, error) {
+	start := n.FindPolygon(from)
+	if start < 0 {
+		return nil, fmt.Errorf("path start %v is outside the navmesh", from)
+	}
+	goal := n.FindPolygon(to)
+	if goal < 0 {
+		return nil, fmt.Errorf("path goal %v is outside the navmesh", to)
+	}
+	if start == goal {
+		return []Point{from, to}, nil
+	}
+
+	corridor := n.corridor(start, goal, to)
+	if corridor == nil {
+		return nil, ErrNoPath
+	}
+
+	portals := make([][2]Point, 0, len(corridor)+2)
+	portals = append(portals, [2]Point{from, from})
+	for _, l := range corridor {
+		portals = append(portals, [2]Point{l.left, l.right})
+	}
+	portals = append(portals, [2]Point{to, to})
+	return stringPull(portals), nil
+}
+
+func (n *NavMesh) FindPathAsync(from, to Point) <-chan PathResult {
+	return findPathAsync(n, from, to)
+}
+
+// A* nad polygony; vrací portály, kterými koridor prochází
+func (n *NavMesh) corridor(start, goal int, to Point) []navLink {
+	cost := make([]float64, len(n.Polygons))
+	via := make([]int, len(n.Polygons)) // index odkazu v předchozím polygonu
+	prev := make([]int, len(n.Polygons))
+	closed := make([]bool, len(n.Polygons))
+	for i := range cost {
+		cost[i] = math.Inf(1)
+		prev[i] = -1
+	}
+
+	h := func(i int) float64 { return n.centers[i].DistanceTo(to) }
+	open := &openList{{node: start, f: h(start)}}
+	cost[start] = 0
+	for open.Len() > 0 {
+		cur := heap.Pop(open).(openEntry).node
+		if closed[cur] {
+			continue
+		}
+		if cur == goal {
+			var links []navLink
+			for p := cur; prev[p] != -1; p = prev[p] {
+				links = append(links, n.links[prev[p]][via[p]])
+			}
+			for i, j := 0, len(links)-1; i < j; i, j = i+1, j-1 {
+				links[i], links[j] = links[j], links[i]
+			}
+			return links
+		}
+		closed[cur] = true
+
+		for k, l := range n.links[cur] {
+			if closed[l.to] {
+				continue
+			}
+			if c := cost[cur] + n.centers[cur].DistanceTo(n.centers[l.to]); c < cost[l.to] {
+				cost[l.to] = c
+				prev[l.to] = cur
+				via[l.to] = k
+				heap.Push(open, openEntry{node: l.to, f: c + h(l.to)})
+			}
+		}
+	}
+	return nil
+}
+
+// Algoritmus trychtýře: nejkratší cesta koridorem portálů (první a poslední portál jsou start a cíl)
+func stringPull(portals [][2]Point) []Point {
+	apex, left, right := portals[0][0], portals[0][0], portals[0][1]
+	apexIndex, leftIndex, rightIndex := 0, 0, 0
+	path := []Point{apex}
+
+	for i := 1; i < len(portals); i++ {
+		l, r := portals[i][0], portals[i][1]
+
+		// Zúžení pravé strany trychtýře
+		if triarea2(apex, right, r) <= 0 {
+			if apex == right || triarea2(apex, left, r) > 0 {
+				right, rightIndex = r, i
+			} else {
+				// Pravá strana přešla přes levou, levý bod se stává novým vrcholem
+				path = append(path, left)
+				apex, apexIndex = left, leftIndex
+				left, right = apex, apex
+				leftIndex, rightIndex = apexIndex, apexIndex
+				i = apexIndex
+				continue
+			}
+		}
+
+		// Zúžení levé strany trychtýře
+		if triarea2(apex, left, l) >= 0 {
+			if apex == left || triarea2(apex, right, l) < 0 {
+				left, leftIndex = l, i
+			} else {
+				path = append(path, right)
+				apex, apexIndex = right, rightIndex
+				left, right = apex, apex
+				leftIndex, rightIndex = apexIndex, apexIndex
+				i = apexIndex
+				continue
+			}
+		}
+	}
+
+	end := portals[len(portals)-1][0]
+	if path[len(path)-1] != end {
+		path = append(path, end)
+	}
+	return path
+}
+
+// Funkce pro vytvoření navmeshe z jednoho průchodného polygonu (rozloží se na konvexní dílky)
+func NavMeshFromPolygon(area Polygon) (*NavMesh, error) {
+	pieces, err := area.ConvexDecomposition()
+	if err != nil {
+		return nil, err
+	}
+	polygons := make([][]Point, len(pieces))
+	for i, piece := range pieces {
+		polygons[i] = piece.Points
+	}
+	return NewNavMesh(polygons)
+}
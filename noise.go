@@ -0,0 +1,323 @@
+package main
+
+import "math"
+
+// Koherentní šum (Perlin, simplex, Worley) určený seedem
+// Stejný seed dává stejný šum na všech strojích, takže procedurální scenérie se při replay nemění
+type Noise struct {
+	seed uint64
+	perm [512]uint8 // permutace 0..255 zopakovaná dvakrát, aby se nemuselo ořezávat index
+}
+
+func NewNoise(seed uint64) *Noise {
+	n := &Noise{seed: seed}
+	p := make([]uint8, 256)
+	for i := range p {
+		p[i] = uint8(i)
+	}
+	Shuffle(NewRand(seed), p)
+	for i := range n.perm {
+		n.perm[i] = p[i&255]
+	}
+	return n
+}
+
+func (n *Noise) Seed() uint64 {
+	return n.seed
+}
+
+func (n *Noise) hash1(i int) int {
+	return int(n.perm[i&255])
+}
+
+func (n *Noise) hash2(i, j int) int {
+	return int(n.perm[int(n.perm[i&255])+j&255])
+}
+
+func (n *Noise) hash3(i, j, k int) int {
+	return int(n.perm[int(n.perm[int(n.perm[i&255])+j&255])+k&255])
+}
+
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func floorInt(x float64) int {
+	return int(math.Floor(x))
+}
+
+// Gradient v 1D: sklon v intervalu -1..1
+func grad1(h int, x float64) float64 {
+	return (float64(h&15)/7.5 - 1) * x
+}
+
+// Gradienty ve 2D: osm směrů po 45°
+var grad2Table = [8][2]float64{
+	{1, 0}, {-1, 0}, {0, 1}, {0, -1},
+	{math.Sqrt2 / 2, math.Sqrt2 / 2}, {-math.Sqrt2 / 2, math.Sqrt2 / 2},
+	{math.Sqrt2 / 2, -math.Sqrt2 / 2}, {-math.Sqrt2 / 2, -math.Sqrt2 / 2},
+}
+
+func grad2(h int, x, y float64) float64 {
+	g := grad2Table[h&7]
+	return g[0]*x + g[1]*y
+}
+
+// Gradienty ve 3D: dvanáct směrů ke středům hran krychle (Perlin 2002)
+func grad3(h int, x, y, z float64) float64 {
+	h &= 15
+	u, v := x, y
+	if h >= 8 {
+		u = y
+	}
+	if h >= 4 {
+		v = z
+		if h == 12 || h == 14 {
+			v = x
+		}
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + v
+}
+
+// Perlinův šum v 1D, hodnoty v intervalu -1..1
+func (n *Noise) Perlin1(x float64) float64 {
+	i := floorInt(x)
+	x -= float64(i)
+	a := grad1(n.hash1(i), x)
+	b := grad1(n.hash1(i+1), x-1)
+	return 2 * lerp(a, b, fade(x))
+}
+
+// Perlinův šum ve 2D, hodnoty přibližně v intervalu -1..1
+func (n *Noise) Perlin2(x, y float64) float64 {
+	i, j := floorInt(x), floorInt(y)
+	x, y = x-float64(i), y-float64(j)
+	u, v := fade(x), fade(y)
+
+	a := lerp(grad2(n.hash2(i, j), x, y), grad2(n.hash2(i+1, j), x-1, y), u)
+	b := lerp(grad2(n.hash2(i, j+1), x, y-1), grad2(n.hash2(i+1, j+1), x-1, y-1), u)
+	return math.Sqrt2 * lerp(a, b, v)
+}
+
+// Perlinův šum ve 3D, hodnoty přibližně v intervalu -1..1
+func (n *Noise) Perlin3(x, y, z float64) float64 {
+	i, j, k := floorInt(x), floorInt(y), floorInt(z)
+	x, y, z = x-float64(i), y-float64(j), z-float64(k)
+	u, v, w := fade(x), fade(y), fade(z)
+
+	corner := func(di, dj, dk int) float64 {
+		return grad3(n.hash3(i+di, j+dj, k+dk), x-float64(di), y-float64(dj), z-float64(dk))
+	}
+	a := lerp(lerp(corner(0, 0, 0), corner(1, 0, 0), u), lerp(corner(0, 1, 0), corner(1, 1, 0), u), v)
+	b := lerp(lerp(corner(0, 0, 1), corner(1, 0, 1), u), lerp(corner(0, 1, 1), corner(1, 1, 1), u), v)
+	return lerp(a, b, w)
+}
+
+// Simplexový šum v 1D, hodnoty přibližně v intervalu -1..1
+func (n *Noise) Simplex1(x float64) float64 {
+	i := floorInt(x)
+	x0 := x - float64(i)
+	x1 := x0 - 1
+
+	t0 := 1 - x0*x0
+	t1 := 1 - x1*x1
+	t0 *= t0
+	t1 *= t1
+	return 3.16 * (t0*t0*grad1(n.hash1(i), x0) + t1*t1*grad1(n.hash1(i+1), x1))
+}
+
+// Konstanty pro zkosení mřížky na trojúhelníky a zpět
+var (
+	simplexF2 = 0.5 * (math.Sqrt(3) - 1)
+	simplexG2 = (3 - math.Sqrt(3)) / 6
+)
+
+const (
+	simplexF3 = 1.0 / 3
+	simplexG3 = 1.0 / 6
+)
+
+// Simplexový šum ve 2D, hodnoty přibližně v intervalu -1..1
+func (n *Noise) Simplex2(x, y float64) float64 {
+	s := (x + y) * simplexF2
+	i, j := floorInt(x+s), floorInt(y+s)
+	t := float64(i+j) * simplexG2
+	x0, y0 := x-(float64(i)-t), y-(float64(j)-t)
+
+	// Ve kterém ze dvou trojúhelníků čtverce bod leží
+	i1, j1 := 0, 1
+	if x0 > y0 {
+		i1, j1 = 1, 0
+	}
+	x1, y1 := x0-float64(i1)+simplexG2, y0-float64(j1)+simplexG2
+	x2, y2 := x0-1+2*simplexG2, y0-1+2*simplexG2
+
+	corner := func(h int, x, y float64) float64 {
+		t := 0.5 - x*x - y*y
+		if t < 0 {
+			return 0
+		}
+		t *= t
+		return t * t * grad2(h, x, y)
+	}
+	sum := corner(n.hash2(i, j), x0, y0) +
+		corner(n.hash2(i+i1, j+j1), x1, y1) +
+		corner(n.hash2(i+1, j+1), x2, y2)
+	return 99 * sum // 70 u původních gradientů délky √2, zde jsou jednotkové
+}
+
+// Simplexový šum ve 3D, hodnoty přibližně v intervalu -1..1
+func (n *Noise) Simplex3(x, y, z float64) float64 {
+	s := (x + y + z) * simplexF3
+	i, j, k := floorInt(x+s), floorInt(y+s), floorInt(z+s)
+	t := float64(i+j+k) * simplexG3
+	x0, y0, z0 := x-(float64(i)-t), y-(float64(j)-t), z-(float64(k)-t)
+
+	// Ve kterém ze šesti čtyřstěnů krychle bod leží
+	var i1, j1, k1, i2, j2, k2 int
+	switch {
+	case x0 >= y0 && y0 >= z0:
+		i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 1, 0
+	case x0 >= y0 && x0 >= z0:
+		i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 0, 1
+	case x0 >= y0:
+		i1, j1, k1, i2, j2, k2 = 0, 0, 1, 1, 0, 1
+	case y0 < z0:
+		i1, j1, k1, i2, j2, k2 = 0, 0, 1, 0, 1, 1
+	case x0 < z0:
+		i1, j1, k1, i2, j2, k2 = 0, 1, 0, 0, 1, 1
+	default:
+		i1, j1, k1, i2, j2, k2 = 0, 1, 0, 1, 1, 0
+	}
+
+	corner := func(di, dj, dk int, g float64) float64 {
+		cx := x0 - float64(di) + g
+		cy := y0 - float64(dj) + g
+		cz := z0 - float64(dk) + g
+		t := 0.6 - cx*cx - cy*cy - cz*cz
+		if t < 0 {
+			return 0
+		}
+		t *= t
+		return t * t * grad3(n.hash3(i+di, j+dj, k+dk), cx, cy, cz)
+	}
+	sum := corner(0, 0, 0, 0) +
+		corner(i1, j1, k1, simplexG3) +
+		corner(i2, j2, k2, 2*simplexG3) +
+		corner(1, 1, 1, 3*simplexG3)
+	return 32 * sum
+}
+
+// Pseudonáhodné číslo buňky mřížky (nezávisí na permutaci, takže má plných 64 bitů)
+func (n *Noise) cellHash(coords ...int) uint64 {
+	h := n.seed
+	for _, c := range coords {
+		h ^= uint64(int64(c)) + 0x9e3779b97f4a7c15 + (h << 6) + (h >> 2)
+		h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+		h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+		h ^= h >> 31
+	}
+	return h
+}
+
+// Složka 0..1 z 16 bitů hashe
+func hashUnit(h uint64, part int) float64 {
+	return float64((h>>(16*part))&0xffff) / 0x10000
+}
+
+// Worleyho (buněčný) šum v 1D: vzdálenost k nejbližšímu bodu, jeden bod v každé jednotkové buňce
+// Hodnoty v intervalu 0..1
+func (n *Noise) Worley1(x float64) float64 {
+	i := floorInt(x)
+	best := math.Inf(1)
+	for di := -1; di <= 1; di++ {
+		c := i + di
+		fx := float64(c) + hashUnit(n.cellHash(c), 0)
+		best = math.Min(best, math.Abs(fx-x))
+	}
+	return math.Min(best, 1)
+}
+
+// Worleyho šum ve 2D, hodnoty přibližně v intervalu 0..1
+func (n *Noise) Worley2(x, y float64) float64 {
+	i, j := floorInt(x), floorInt(y)
+	best := math.Inf(1)
+	for di := -1; di <= 1; di++ {
+		for dj := -1; dj <= 1; dj++ {
+			ci, cj := i+di, j+dj
+			h := n.cellHash(ci, cj)
+			dx := float64(ci) + hashUnit(h, 0) - x
+			dy := float64(cj) + hashUnit(h, 1) - y
+			best = math.Min(best, dx*dx+dy*dy)
+		}
+	}
+	return math.Min(math.Sqrt(best), 1)
+}
+
+// Worleyho šum ve 3D, hodnoty přibližně v intervalu 0..1
+func (n *Noise) Worley3(x, y, z float64) float64 {
+	i, j, k := floorInt(x), floorInt(y), floorInt(z)
+	best := math.Inf(1)
+	for di := -1; di <= 1; di++ {
+		for dj := -1; dj <= 1; dj++ {
+			for dk := -1; dk <= 1; dk++ {
+				ci, cj, ck := i+di, j+dj, k+dk
+				h := n.cellHash(ci, cj, ck)
+				dx := float64(ci) + hashUnit(h, 0) - x
+				dy := float64(cj) + hashUnit(h, 1) - y
+				dz := float64(ck) + hashUnit(h, 2) - z
+				best = math.Min(best, dx*dx+dy*dy+dz*dz)
+			}
+		}
+	}
+	return math.Min(math.Sqrt(best), 1)
+}
+
+// Fraktální Brownův pohyb: součet oktáv šumu se stoupající frekvencí a klesající amplitudou
+type FBM struct {
+	Octaves    int
+	Lacunarity float64 // násobek frekvence mezi oktávami
+	Gain       float64 // násobek amplitudy mezi oktávami
+}
+
+var DefaultFBM = FBM{Octaves: 5, Lacunarity: 2, Gain: 0.5}
+
+// Součet oktáv; výsledek je vydělený součtem amplitud, takže zůstává v rozsahu zdrojového šumu
+func (f FBM) sum(sample func(freq float64) float64) float64 {
+	total, amp, norm, freq := 0.0, 1.0, 0.0, 1.0
+	for o := 0; o < max(f.Octaves, 1); o++ {
+		total += amp * sample(freq)
+		norm += amp
+		amp *= f.Gain
+		freq *= f.Lacunarity
+	}
+	if norm == 0 {
+		return 0
+	}
+	return total / norm
+}
+
+// Funkce pro fBm z jednorozměrného šumu (např. Noise.Perlin1)
+func (f FBM) Sample1(noise func(x float64) float64, x float64) float64 {
+	return f.sum(func(freq float64) float64 {
+		return noise(x * freq)
+	})
+}
+
+func (f FBM) Sample2(noise func(x, y float64) float64, x, y float64) float64 {
+	return f.sum(func(freq float64) float64 {
+		return noise(x*freq, y*freq)
+	})
+}
+
+func (f FBM) Sample3(noise func(x, y, z float64) float64, x, y, z float64) float64 {
+	return f.sum(func(freq float64) float64 {
+		return noise(x*freq, y*freq, z*freq)
+	})
+}
@@ -0,0 +1,497 @@
+package main
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Chyba vracená, když mezi startem a cílem žádná cesta nevede
+var ErrNoPath = errors.New("no path found")
+
+// Společné rozhraní hledání cesty pro mřížku i navmesh
+type Pathfinder interface {
+	FindPath(from, to Point) ([]Point, error)
+	FindPathAsync(from, to Point) <-chan PathResult
+}
+
+// Výsledek asynchronního hledání cesty
+type PathResult struct {
+	Path []Point
+	Err  error
+}
+
+// Mřížka průchodnosti pro hledání cesty
+// Buňka (x, y) pokrývá ve světě čtverec o straně CellSize začínající v Origin + (x, y) * CellSize
+type Grid struct {
+	Width, Height int
+	CellSize      float64
+	Origin        Point
+	Diagonal      bool // povolí šikmý pohyb (nikdy ne přes roh překážky)
+	JumpPoints    bool // hledání pomocí Jump Point Search místo prostého A* (jen s Diagonal)
+	Smooth        bool // vyhlazení výsledné cesty podle přímé viditelnosti
+
+	blocked []bool
+}
+
+var (
+	_ Pathfinder = (*Grid)(nil)
+	_ Pathfinder = (*NavMesh)(nil)
+)
+
+// Funkce pro vytvoření mřížky, ve které jsou všechny buňky průchodné
+// Nekladná velikost buňky se nahradí DefaultCellSize (jako u NewSpatialHash)
+func NewGrid(width, height int, cellSize float64) *Grid {
+	if cellSize <= 0 {
+		cellSize = DefaultCellSize
+	}
+	width, height = max(width, 0), max(height, 0)
+	return &Grid{
+		Width:    width,
+		Height:   height,
+		CellSize: cellSize,
+		Diagonal: true,
+		Smooth:   true,
+		blocked:  make([]bool, width*height),
+	}
+}
+
+// Funkce pro vytvoření mřížky z tilemapy (tiles[y][x]); walkable rozhoduje, které dlaždice jsou průchodné
+func GridFromTiles(tiles [][]int, cellSize float64, walkable func(tile int) bool) *Grid {
+	width := 0
+	for _, row := range tiles {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	g := NewGrid(width, len(tiles), cellSize)
+	for y := range g.blocked {
+		g.blocked[y] = true
+	}
+	for y, row := range tiles {
+		for x, tile := range row {
+			g.SetWalkable(x, y, walkable(tile))
+		}
+	}
+	return g
+}
+
+// Funkce pro vytvoření mřížky z překážek ve scéně
+// Neprůchodné jsou buňky, které překrývá kolizní tvar statického tělesa s kategorií v masce
+// Nekladná velikost buňky se nahradí DefaultCellSize, převrácený obdélník dá prázdnou mřížku
+func (s *Scene) BuildGrid(bounds Rectangle, cellSize float64, mask uint32) *Grid {
+	if cellSize <= 0 {
+		cellSize = DefaultCellSize
+	}
+	width := max(int(math.Ceil((bounds.Max.X-bounds.Min.X)/cellSize)), 0)
+	height := max(int(math.Ceil((bounds.Max.Y-bounds.Min.Y)/cellSize)), 0)
+	g := NewGrid(width, height, cellSize)
+	g.Origin = bounds.Min
+
+	// Buňky se mírně zmenší, aby překážka jen se dotýkající hrany buňku neblokovala
+	inset := cellSize * 1e-6
+	for _, l := range s.IterateLayersInOrder() {
+		for _, sp := range l.Sprites {
+			b := &sp.GetSprite().Body
+			if b.Mode != Static || b.Sensor || b.Category&mask == 0 {
+				continue
+			}
+			c := sp.GetSprite().Collider()
+			x0, y0 := g.cellOf(c.Bounds().Min)
+			x1, y1 := g.cellOf(c.Bounds().Max)
+			for y := max(y0, 0); y <= min(y1, height-1); y++ {
+				for x := max(x0, 0); x <= min(x1, width-1); x++ {
+					cell := g.CellRect(x, y)
+					cell.Min = cell.Min.AddVector(Vector{X: inset, Y: inset})
+					cell.Max = cell.Max.AddVector(Vector{X: -inset, Y: -inset})
+					if _, hit := CollideShapes(cell.Polygon(), c); hit {
+						g.SetWalkable(x, y, false)
+					}
+				}
+			}
+		}
+	}
+	return g
+}
+
+// Kopie mřížky (např. pro hledání cesty na pozadí, zatímco hra mřížku mění)
+func (g *Grid) Clone() *Grid {
+	c := *g
+	c.blocked = append([]bool(nil), g.blocked...)
+	return &c
+}
+
+func (g *Grid) inside(x, y int) bool {
+	return x >= 0 && y >= 0 && x < g.Width && y < g.Height
+}
+
+// Buňky mimo mřížku jsou neprůchodné
+func (g *Grid) Walkable(x, y int) bool {
+	return g.inside(x, y) && !g.blocked[y*g.Width+x]
+}
+
+func (g *Grid) SetWalkable(x, y int, walkable bool) {
+	if g.inside(x, y) {
+		g.blocked[y*g.Width+x] = !walkable
+	}
+}
+
+// Funkce pro ruční "malování" průchodnosti do všech buněk, které překrývá obdélník ve světě
+func (g *Grid) Paint(r Rectangle, walkable bool) {
+	x0, y0 := g.cellOf(r.Min)
+	x1, y1 := g.cellOf(r.Max)
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			g.SetWalkable(x, y, walkable)
+		}
+	}
+}
+
+func (g *Grid) cellOf(p Point) (int, int) {
+	return int(math.Floor((p.X - g.Origin.X) / g.CellSize)), int(math.Floor((p.Y - g.Origin.Y) / g.CellSize))
+}
+
+// Funkce vrací buňku, ve které leží bod ve světě
+func (g *Grid) CellAt(p Point) (x, y int, ok bool) {
+	x, y = g.cellOf(p)
+	return x, y, g.inside(x, y)
+}
+
+func (g *Grid) CellRect(x, y int) Rectangle {
+	min := Point{X: g.Origin.X + float64(x)*g.CellSize, Y: g.Origin.Y + float64(y)*g.CellSize}
+	return Rectangle{Min: min, Max: Point{X: min.X + g.CellSize, Y: min.Y + g.CellSize}}
+}
+
+func (g *Grid) CellCenter(x, y int) Point {
+	return Point{X: g.Origin.X + (float64(x)+0.5)*g.CellSize, Y: g.Origin.Y + (float64(y)+0.5)*g.CellSize}
+}
+
+// Funkce pro nalezení cesty mezi dvěma body ve světě
+// Cesta začíná v from, končí v to a mezi nimi vede přes středy buněk (nebo jen přes zlomy, pokud je Smooth)
+func (g *Grid) FindPath(from, to Point) ([]Point, error) {
+	sx, sy, ok := g.CellAt(from)
+	if !ok || !g.Walkable(sx, sy) {
+		return nil, fmt.Errorf("path start %v is not walkable", from)
+	}
+	tx, ty, ok := g.CellAt(to)
+	if !ok || !g.Walkable(tx, ty) {
+		return nil, fmt.Errorf("path goal %v is not walkable", to)
+	}
+
+	successors := g.neighbors
+	if g.JumpPoints && g.Diagonal {
+		successors = g.jumpSuccessors
+	}
+	cells := g.search(sy*g.Width+sx, ty*g.Width+tx, successors)
+	if cells == nil {
+		return nil, ErrNoPath
+	}
+
+	path := make([]Point, 0, len(cells)+1)
+	path = append(path, from)
+	for i := 1; i < len(cells)-1; i++ {
+		path = append(path, g.CellCenter(cells[i]%g.Width, cells[i]/g.Width))
+	}
+	path = append(path, to)
+
+	if g.Smooth {
+		path = g.SmoothPath(path)
+	}
+	return path, nil
+}
+
+// Funkce spustí hledání cesty na pozadí nad kopií mřížky; výsledek přijde kanálem
+func (g *Grid) FindPathAsync(from, to Point) <-chan PathResult {
+	return findPathAsync(g.Clone(), from, to)
+}
+
+func findPathAsync(pf Pathfinder, from, to Point) <-chan PathResult {
+	ch := make(chan PathResult, 1)
+	go func() {
+		path, err := pf.FindPath(from, to)
+		ch <- PathResult{Path: path, Err: err}
+	}()
+	return ch
+}
+
+// Vzdálenost dvou buněk při pohybu po osách a diagonálách (octile), resp. manhattanská bez diagonál
+func (g *Grid) distance(a, b int) float64 {
+	dx := math.Abs(float64(a%g.Width - b%g.Width))
+	dy := math.Abs(float64(a/g.Width - b/g.Width))
+	if !g.Diagonal {
+		return dx + dy
+	}
+	return math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+}
+
+// Otevřený seznam A* (binární halda podle f)
+type openEntry struct {
+	node int
+	f    float64
+}
+
+type openList []openEntry
+
+func (o openList) Len() int            { return len(o) }
+func (o openList) Less(i, j int) bool  { return o[i].f < o[j].f }
+func (o openList) Swap(i, j int)       { o[i], o[j] = o[j], o[i] }
+func (o *openList) Push(x interface{}) { *o = append(*o, x.(openEntry)) }
+func (o *openList) Pop() interface{} {
+	old := *o
+	e := old[len(old)-1]
+	*o = old[:len(old)-1]
+	return e
+}
+
+// A* nad buňkami; successors vyjmenuje následníky buňky (sousedy, nebo skokové body JPS)
+func (g *Grid) search(start, goal int, successors func(cur, parent, goal int, fn func(next int))) []int {
+	n := g.Width * g.Height
+	cost := make([]float64, n)
+	parent := make([]int32, n)
+	closed := make([]bool, n)
+	for i := range cost {
+		cost[i] = math.Inf(1)
+		parent[i] = -1
+	}
+
+	open := &openList{{node: start, f: g.distance(start, goal)}}
+	cost[start] = 0
+	for open.Len() > 0 {
+		cur := heap.Pop(open).(openEntry).node
+		if closed[cur] {
+			continue
+		}
+		if cur == goal {
+			var cells []int
+			for c := cur; c != -1; c = int(parent[c]) {
+				cells = append(cells, c)
+			}
+			for i, j := 0, len(cells)-1; i < j; i, j = i+1, j-1 {
+				cells[i], cells[j] = cells[j], cells[i]
+			}
+			return cells
+		}
+		closed[cur] = true
+
+		successors(cur, int(parent[cur]), goal, func(next int) {
+			if closed[next] {
+				return
+			}
+			if c := cost[cur] + g.distance(cur, next); c < cost[next] {
+				cost[next] = c
+				parent[next] = int32(cur)
+				heap.Push(open, openEntry{node: next, f: c + g.distance(next, goal)})
+			}
+		})
+	}
+	return nil
+}
+
+// Průchodní sousedé buňky; šikmo jen tehdy, když jsou volné obě sousední buňky na osách
+func (g *Grid) neighbors(cur, parent, goal int, fn func(next int)) {
+	x, y := cur%g.Width, cur/g.Width
+	for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		if g.Walkable(x+d[0], y+d[1]) {
+			fn((y+d[1])*g.Width + x + d[0])
+		}
+	}
+	if !g.Diagonal {
+		return
+	}
+	for _, d := range [4][2]int{{1, 1}, {-1, 1}, {1, -1}, {-1, -1}} {
+		if g.Walkable(x+d[0], y) && g.Walkable(x, y+d[1]) && g.Walkable(x+d[0], y+d[1]) {
+			fn((y+d[1])*g.Width + x + d[0])
+		}
+	}
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// Následníci pro Jump Point Search: sousedé prořezaní podle směru příchodu a z nich skokové body
+func (g *Grid) jumpSuccessors(cur, parent, goal int, fn func(next int)) {
+	x, y := cur%g.Width, cur/g.Width
+	gx, gy := goal%g.Width, goal/g.Width
+
+	emit := func(nx, ny int) {
+		if jx, jy, ok := g.jump(nx, ny, x, y, gx, gy); ok {
+			fn(jy*g.Width + jx)
+		}
+	}
+
+	if parent == -1 {
+		g.neighbors(cur, parent, goal, func(next int) {
+			emit(next%g.Width, next/g.Width)
+		})
+		return
+	}
+
+	dx, dy := sign(x-parent%g.Width), sign(y-parent/g.Width)
+	w := g.Walkable
+	switch {
+	case dx != 0 && dy != 0:
+		if w(x, y+dy) {
+			emit(x, y+dy)
+		}
+		if w(x+dx, y) {
+			emit(x+dx, y)
+		}
+		if w(x, y+dy) && w(x+dx, y) {
+			emit(x+dx, y+dy)
+		}
+	case dx != 0:
+		next, down, up := w(x+dx, y), w(x, y+1), w(x, y-1)
+		if next {
+			emit(x+dx, y)
+			if down {
+				emit(x+dx, y+1)
+			}
+			if up {
+				emit(x+dx, y-1)
+			}
+		}
+		if down {
+			emit(x, y+1)
+		}
+		if up {
+			emit(x, y-1)
+		}
+	default:
+		next, right, left := w(x, y+dy), w(x+1, y), w(x-1, y)
+		if next {
+			emit(x, y+dy)
+			if right {
+				emit(x+1, y+dy)
+			}
+			if left {
+				emit(x-1, y+dy)
+			}
+		}
+		if right {
+			emit(x+1, y)
+		}
+		if left {
+			emit(x-1, y)
+		}
+	}
+}
+
+// Skok z buňky (px, py) přes (x, y) v jejich směru, dokud nenarazí na cíl nebo na buňku s vynuceným sousedem
+func (g *Grid) jump(x, y, px, py, gx, gy int) (int, int, bool) {
+	w := g.Walkable
+	dx, dy := x-px, y-py
+	if !w(x, y) {
+		return 0, 0, false
+	}
+	if x == gx && y == gy {
+		return x, y, true
+	}
+
+	switch {
+	case dx != 0 && dy != 0:
+		// Šikmý skok se zastaví, pokud některý z rovných skoků z této buňky něco najde
+		if _, _, ok := g.jump(x+dx, y, x, y, gx, gy); ok {
+			return x, y, true
+		}
+		if _, _, ok := g.jump(x, y+dy, x, y, gx, gy); ok {
+			return x, y, true
+		}
+	case dx != 0:
+		if (w(x, y-1) && !w(x-dx, y-1)) || (w(x, y+1) && !w(x-dx, y+1)) {
+			return x, y, true
+		}
+	default:
+		if (w(x-1, y) && !w(x-1, y-dy)) || (w(x+1, y) && !w(x+1, y-dy)) {
+			return x, y, true
+		}
+	}
+
+	if w(x+dx, y) && w(x, y+dy) {
+		return g.jump(x+dx, y+dy, x, y, gx, gy)
+	}
+	return 0, 0, false
+}
+
+// Funkce pro vyhlazení cesty: vynechá body, mezi kterými je přímá viditelnost po průchodných buňkách
+func (g *Grid) SmoothPath(path []Point) []Point {
+	return smoothPath(path, g.LineOfSight)
+}
+
+func smoothPath(path []Point, visible func(a, b Point) bool) []Point {
+	if len(path) < 3 {
+		return path
+	}
+	out := []Point{path[0]}
+	for i := 0; i < len(path)-1; {
+		j := len(path) - 1
+		for j > i+1 && !visible(path[i], path[j]) {
+			j--
+		}
+		out = append(out, path[j])
+		i = j
+	}
+	return out
+}
+
+// Funkce zjistí, zda úsečka mezi dvěma body vede jen přes průchodné buňky (průchod buňkami DDA)
+// Přes roh dvou buněk projde jen tehdy, když jsou volné obě buňky kolem rohu
+func (g *Grid) LineOfSight(a, b Point) bool {
+	x, y, ok := g.CellAt(a)
+	x1, y1, ok1 := g.CellAt(b)
+	if !ok || !ok1 || !g.Walkable(x, y) {
+		return false
+	}
+
+	d := subPoints(b, a)
+	axis := func(pos, delta, origin float64, cell int) (step int, tMax, tDelta float64) {
+		switch {
+		case delta > 0:
+			edge := origin + float64(cell+1)*g.CellSize
+			return 1, (edge - pos) / delta, g.CellSize / delta
+		case delta < 0:
+			edge := origin + float64(cell)*g.CellSize
+			return -1, (edge - pos) / delta, -g.CellSize / delta
+		}
+		return 0, math.Inf(1), math.Inf(1)
+	}
+	stepX, tMaxX, tDeltaX := axis(a.X, d.X, g.Origin.X, x)
+	stepY, tMaxY, tDeltaY := axis(a.Y, d.Y, g.Origin.Y, y)
+
+	for n := abs(x1-x) + abs(y1-y); n > 0 && (x != x1 || y != y1); n-- {
+		switch {
+		case math.Abs(tMaxX-tMaxY) < collisionEpsilon:
+			if !g.Walkable(x+stepX, y) || !g.Walkable(x, y+stepY) {
+				return false
+			}
+			x, y = x+stepX, y+stepY
+			tMaxX += tDeltaX
+			tMaxY += tDeltaY
+		case tMaxX < tMaxY:
+			x += stepX
+			tMaxX += tDeltaX
+		default:
+			y += stepY
+			tMaxY += tDeltaY
+		}
+		if !g.Walkable(x, y) {
+			return false
+		}
+	}
+	return x == x1 && y == y1
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,213 @@
+package main
+
+import "sort"
+
+// Režim fyzikálního těla
+type BodyMode int
+
+const (
+	Dynamic   BodyMode = iota // pohybuje se podle sil, gravitace a kolizí
+	Kinematic                 // pohybuje se jen podle Movement, síly ho neovlivní
+	Static                    // nehybné
+)
+
+// Fyzikální vlastnosti spritu
+type Body struct {
+	Mode         BodyMode
+	Mass         float64 // hmotnost; nekladná hodnota se chová jako 1
+	Damping      float64 // lineární tlumení rychlosti (za sekundu)
+	GravityScale float64 // násobek gravitace scény
+	MaxSpeed     float64 // maximální rychlost, 0 = bez omezení
+	Force        Vector  // síla akumulovaná pro aktuální krok, po kroku se vynuluje
+	Material     Material
+	Category     uint32 // bitová kategorie těla
+	Mask         uint32 // kategorie, se kterými tělo koliduje
+	Sensor       bool   // trigger: kolize se hlásí, ale neřeší
+	CCD          bool   // kontinuální detekce kolizí pro rychlá tělesa (projektily)
+	OneWay       bool   // jednosměrná plošina: zastaví jen tělesa dopadající shora
+
+	sweep func(Vector) Vector // omezení posunu při CCD, nastavuje Physics.Step
+}
+
+// Kolizní kategorie
+const (
+	CategoryDefault uint32 = 1
+	MaskAll         uint32 = ^uint32(0)
+)
+
+// Materiál povrchu těla pro řešení kolizí
+type Material struct {
+	Restitution float64 // pružnost odrazu 0..1
+	Friction    float64 // koeficient tření
+}
+
+var DefaultMaterial = Material{Restitution: 0, Friction: 0.3}
+
+func NewBody() Body {
+	return Body{
+		Mode:         Dynamic,
+		Mass:         1,
+		GravityScale: 1,
+		Material:     DefaultMaterial,
+		Category:     CategoryDefault,
+		Mask:         MaskAll,
+	}
+}
+
+// Dvě těla spolu kolidují, jen pokud kategorie každého z nich je v masce druhého
+func (b *Body) CollidesWith(o *Body) bool {
+	return b.Category&o.Mask != 0 && o.Category&b.Mask != 0
+}
+
+// Převrácená hodnota hmotnosti; statická a kinematická těla mají nekonečnou hmotnost
+func (b *Body) InvMass() float64 {
+	if b.Mode != Dynamic {
+		return 0
+	}
+	if b.Mass <= 0 {
+		return 1
+	}
+	return 1 / b.Mass
+}
+
+func (b *Body) ApplyForce(f Vector) {
+	b.Force = b.Force.Add(f)
+}
+
+// Okamžitá změna hybnosti (např. výstřel, odraz)
+func (s *Sprite) ApplyImpulse(j Vector) {
+	s.Movement = s.Movement.Add(j.Scale(s.Body.InvMass()))
+}
+
+// Fyzikální svět scény
+type Physics struct {
+	Gravity    Vector
+	Index      *SpatialHash // broad-phase index všech spritů scény, aktualizuje se v každém kroku
+	Iterations int          // počet iterací řešiče kolizí
+	Joints     []Joint
+
+	contacts []contactConstraint
+	touches  []touch            // dotýkající se páry v aktuálním kroku
+	touching map[spritePair]int // páry z minulého kroku (pro enter/stay/exit)
+
+	order map[Spriter]int // pořadí spritů ve scéně, aby bylo zpracování párů deterministické
+	pairs []spritePair
+}
+
+type spritePair struct {
+	A, B Spriter
+}
+
+func NewPhysics(gravity Vector) *Physics {
+	return &Physics{
+		Gravity:    gravity,
+		Index:      NewSpatialHash(DefaultCellSize),
+		Iterations: DefaultIterations,
+		order:      make(map[Spriter]int),
+		touching:   make(map[spritePair]int),
+	}
+}
+
+func (p *Physics) AddJoint(j Joint) {
+	p.Joints = append(p.Joints, j)
+}
+
+func (p *Physics) RemoveJoint(j Joint) bool {
+	for i, jj := range p.Joints {
+		if jj == j {
+			p.Joints = append(p.Joints[:i], p.Joints[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Funkce pro provedení jednoho pevného kroku fyziky nad všemi sprity scény
+func (p *Physics) Step(scene *Scene, dt float64) {
+	// Index musí být aktuální už při integraci, protože z něj CCD vybírá překážky
+	p.UpdateIndex(scene)
+
+	for _, j := range p.Joints {
+		j.ApplyForces(dt)
+	}
+
+	for _, l := range scene.IterateLayersInOrder() {
+		for _, sp := range l.Sprites {
+			s := sp.GetSprite()
+			if s.Body.Mode == Dynamic && s.Body.GravityScale != 0 {
+				mass := 1 / s.Body.InvMass()
+				s.Body.ApplyForce(p.Gravity.Scale(s.Body.GravityScale * mass))
+			}
+			if s.Body.CCD {
+				s.Body.sweep = func(motion Vector) Vector {
+					return p.sweepMotion(sp, motion)
+				}
+			}
+			sp.ApplyPhysics(float32(dt))
+			s.Body.sweep = nil
+		}
+	}
+
+	p.UpdateIndex(scene)
+	p.detectContacts()
+	p.resolveCollisions(dt)
+	p.dispatchCollisionEvents()
+}
+
+// Funkce pro synchronizaci broad-phase indexu se scénou
+// Sprity, které ze scény zmizely, se z indexu odstraní
+func (p *Physics) UpdateIndex(scene *Scene) {
+	for sp := range p.order {
+		delete(p.order, sp)
+	}
+
+	n := 0
+	for _, l := range scene.IterateLayersInOrder() {
+		for _, sp := range l.Sprites {
+			p.order[sp] = n
+			n++
+			p.Index.Update(sp, sp.GetSprite().indexBounds())
+		}
+	}
+
+	if p.Index.Len() > len(p.order) {
+		p.Index.Each(func(sp Spriter) {
+			if _, exists := p.order[sp]; !exists {
+				p.Index.Remove(sp)
+			}
+		})
+	}
+}
+
+// Kandidátní páry z broad-phase seřazené podle pořadí spritů ve scéně
+func (p *Physics) candidatePairs() []spritePair {
+	p.pairs = p.pairs[:0]
+	p.Index.Pairs(func(a, b Spriter) {
+		if p.order[a] > p.order[b] {
+			a, b = b, a
+		}
+		p.pairs = append(p.pairs, spritePair{A: a, B: b})
+	})
+
+	sort.Slice(p.pairs, func(i, j int) bool {
+		ai, aj := p.order[p.pairs[i].A], p.order[p.pairs[j].A]
+		if ai != aj {
+			return ai < aj
+		}
+		return p.order[p.pairs[i].B] < p.order[p.pairs[j].B]
+	})
+	return p.pairs
+}
+
+// Funkce zavolá fn pro každý pár spritů, které se skutečně dotýkají a podle masek spolu kolidují
+func (p *Physics) Contacts(fn func(a, b Spriter, m Manifold)) {
+	for _, pair := range p.candidatePairs() {
+		sa, sb := pair.A.GetSprite(), pair.B.GetSprite()
+		if !sa.Body.CollidesWith(&sb.Body) {
+			continue
+		}
+		if m, ok := sa.Contact(sb); ok {
+			fn(pair.A, pair.B, m)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Jednoduchý polygon (bez děr a bez samoprotínání) zadaný vrcholy po obvodu
+// Orientace se počítá v matematických osách (Y nahoru): kladný obsah = proti směru hodinových ručiček;
+// na obrazovce s osou Y dolů se proto stejný polygon jeví jako otočený po směru
+type Polygon struct {
+	Points []Point
+}
+
+// Orientace polygonu
+type Winding int
+
+const (
+	Degenerate Winding = iota
+	CounterClockwise
+	Clockwise
+)
+
+// Vektorový součin (a - o) x (b - o); kladný, když o -> a -> b zatáčí doleva
+func cross(o, a, b Point) float64 {
+	return (a.X-o.X)*(b.Y-o.Y) - (a.Y-o.Y)*(b.X-o.X)
+}
+
+// Orientovaný obsah (shoelace)
+func (p Polygon) SignedArea() float64 {
+	sum := 0.0
+	for i, a := range p.Points {
+		b := p.Points[(i+1)%len(p.Points)]
+		sum += a.X*b.Y - b.X*a.Y
+	}
+	return sum / 2
+}
+
+func (p Polygon) Area() float64 {
+	return math.Abs(p.SignedArea())
+}
+
+func (p Polygon) Perimeter() float64 {
+	sum := 0.0
+	for i, a := range p.Points {
+		sum += subPoints(p.Points[(i+1)%len(p.Points)], a).Length()
+	}
+	return sum
+}
+
+func (p Polygon) Winding() Winding {
+	a := p.SignedArea()
+	switch {
+	case a > collisionEpsilon:
+		return CounterClockwise
+	case a < -collisionEpsilon:
+		return Clockwise
+	}
+	return Degenerate
+}
+
+// Kopie s opačným pořadím vrcholů
+func (p Polygon) Reverse() Polygon {
+	pts := make([]Point, len(p.Points))
+	for i, pt := range p.Points {
+		pts[len(pts)-1-i] = pt
+	}
+	return Polygon{Points: pts}
+}
+
+// Kopie orientovaná proti směru hodinových ručiček
+func (p Polygon) CounterClockwise() Polygon {
+	if p.Winding() == Clockwise {
+		return p.Reverse()
+	}
+	return Polygon{Points: append([]Point(nil), p.Points...)}
+}
+
+func (p Polygon) Bounds() Rectangle {
+	return BoundingBox(p.Points)
+}
+
+// Těžiště plochy; u degenerovaného polygonu průměr vrcholů
+func (p Polygon) Centroid() Point {
+	a := p.SignedArea()
+	if math.Abs(a) < collisionEpsilon {
+		if len(p.Points) == 0 {
+			return Point{}
+		}
+		return centroidOf(p.Points)
+	}
+	var cx, cy float64
+	for i, v := range p.Points {
+		w := p.Points[(i+1)%len(p.Points)]
+		f := v.X*w.Y - w.X*v.Y
+		cx += (v.X + w.X) * f
+		cy += (v.Y + w.Y) * f
+	}
+	return Point{X: cx / (6 * a), Y: cy / (6 * a)}
+}
+
+// Test bodu v polygonu (pravidlo sudé-liché); body na hraně patří dovnitř
+func (p Polygon) Contains(pt Point) bool {
+	inside := false
+	n := len(p.Points)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := p.Points[i], p.Points[j]
+		if onSegment(pt, a, b) {
+			return true
+		}
+		if (a.Y > pt.Y) != (b.Y > pt.Y) && pt.X < (b.X-a.X)*(pt.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func onSegment(p, a, b Point) bool {
+	if math.Abs(cross(a, b, p)) > collisionEpsilon*math.Max(1, subPoints(b, a).Length()) {
+		return false
+	}
+	return p.X >= math.Min(a.X, b.X)-collisionEpsilon && p.X <= math.Max(a.X, b.X)+collisionEpsilon &&
+		p.Y >= math.Min(a.Y, b.Y)-collisionEpsilon && p.Y <= math.Max(a.Y, b.Y)+collisionEpsilon
+}
+
+func (p Polygon) IsConvex() bool {
+	return len(p.Points) >= 3 && isConvex(p.Points)
+}
+
+func (p Polygon) Translate(v Vector) Polygon {
+	pts := make([]Point, len(p.Points))
+	for i, pt := range p.Points {
+		pts[i] = pt.AddVector(v)
+	}
+	return Polygon{Points: pts}
+}
+
+func (p Polygon) Transform(m Affine2D) Polygon {
+	pts := make([]Point, len(p.Points))
+	for i, pt := range p.Points {
+		pts[i] = m.TransformPoint(pt)
+	}
+	return Polygon{Points: pts}
+}
+
+// Konvexní obal množiny bodů (monotónní řetězec), orientovaný proti směru hodinových ručiček
+func ConvexHull(points []Point) Polygon {
+	pts := append([]Point(nil), points...)
+	sort.Slice(pts, func(i, j int) bool {
+		if pts[i].X != pts[j].X {
+			return pts[i].X < pts[j].X
+		}
+		return pts[i].Y < pts[j].Y
+	})
+	if len(pts) < 3 {
+		return Polygon{Points: pts}
+	}
+
+	hull := make([]Point, 0, 2*len(pts))
+	for _, p := range pts {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(pts) - 2; i >= 0; i-- {
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], pts[i]) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, pts[i])
+	}
+	return Polygon{Points: hull[:len(hull)-1]}
+}
+
+// Triangulace ořezáváním uší; trojúhelníky jsou orientované proti směru hodinových ručiček
+func (p Polygon) Triangulate() ([][3]Point, error) {
+	tris, err := p.triangulateIndices()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([][3]Point, len(tris))
+	for i, t := range tris {
+		ret[i] = [3]Point{p.Points[t[0]], p.Points[t[1]], p.Points[t[2]]}
+	}
+	return ret, nil
+}
+
+// Trojúhelníky jako indexy vrcholů, orientované proti směru hodinových ručiček
+func (p Polygon) triangulateIndices() ([][3]int, error) {
+	n := len(p.Points)
+	if n < 3 {
+		return nil, fmt.Errorf("polygon needs at least 3 vertices")
+	}
+
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	if p.SignedArea() < 0 {
+		for i := range idx {
+			idx[i] = n - 1 - i
+		}
+	}
+
+	var tris [][3]int
+	pt := func(i int) Point { return p.Points[idx[i]] }
+	for len(idx) > 3 {
+		m := len(idx)
+		found := false
+		for i := 0; i < m; i++ {
+			a, b, c := pt((i+m-1)%m), pt(i), pt((i+1)%m)
+			turn := cross(a, b, c)
+			if math.Abs(turn) < collisionEpsilon {
+				// Vrchol na přímce se odstraní bez trojúhelníku
+				idx = append(idx[:i], idx[i+1:]...)
+				found = true
+				break
+			}
+			if turn < 0 || !p.isEar(idx, i) {
+				continue
+			}
+			tris = append(tris, [3]int{idx[(i+m-1)%m], idx[i], idx[(i+1)%m]})
+			idx = append(idx[:i], idx[i+1:]...)
+			found = true
+			break
+		}
+		if !found {
+			return nil, fmt.Errorf("polygon is not simple")
+		}
+	}
+	if cross(pt(0), pt(1), pt(2)) > collisionEpsilon {
+		tris = append(tris, [3]int{idx[0], idx[1], idx[2]})
+	}
+	return tris, nil
+}
+
+// Ucho: v trojúhelníku kolem vrcholu i neleží žádný jiný vrchol
+func (p Polygon) isEar(idx []int, i int) bool {
+	m := len(idx)
+	ia, ib, ic := idx[(i+m-1)%m], idx[i], idx[(i+1)%m]
+	a, b, c := p.Points[ia], p.Points[ib], p.Points[ic]
+	for _, k := range idx {
+		if k == ia || k == ib || k == ic {
+			continue
+		}
+		q := p.Points[k]
+		if q == a || q == b || q == c {
+			continue
+		}
+		if cross(a, b, q) >= 0 && cross(b, c, q) >= 0 && cross(c, a, q) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Rozklad na konvexní polygony (Hertel-Mehlhorn): z triangulace se odstraní úhlopříčky,
+// jejichž odstranění zachová konvexnost; dílky jsou orientované proti směru hodinových ručiček
+func (p Polygon) ConvexDecomposition() ([]Polygon, error) {
+	if p.IsConvex() {
+		return []Polygon{p.CounterClockwise()}, nil
+	}
+	tris, err := p.triangulateIndices()
+	if err != nil {
+		return nil, err
+	}
+	pieces := make([][]int, len(tris))
+	for i, t := range tris {
+		pieces[i] = []int{t[0], t[1], t[2]}
+	}
+
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(pieces) && !merged; i++ {
+			for j := i + 1; j < len(pieces) && !merged; j++ {
+				if m, ok := p.mergePieces(pieces[i], pieces[j]); ok {
+					pieces[i] = m
+					pieces = append(pieces[:j], pieces[j+1:]...)
+					merged = true
+				}
+			}
+		}
+	}
+
+	ret := make([]Polygon, len(pieces))
+	for i, piece := range pieces {
+		pts := make([]Point, len(piece))
+		for k, v := range piece {
+			pts[k] = p.Points[v]
+		}
+		ret[i] = Polygon{Points: pts}
+	}
+	return ret, nil
+}
+
+// Spojení dvou dílků přes společnou hranu, pokud je výsledek konvexní
+func (p Polygon) mergePieces(a, b []int) ([]int, bool) {
+	for i := range a {
+		u, v := a[i], a[(i+1)%len(a)]
+		for j := range b {
+			if b[j] != v || b[(j+1)%len(b)] != u {
+				continue
+			}
+			// a od v dokola k u, potom b od u k v bez koncových bodů
+			merged := make([]int, 0, len(a)+len(b)-2)
+			for k := 1; k <= len(a); k++ {
+				merged = append(merged, a[(i+k)%len(a)])
+			}
+			for k := 2; k < len(b); k++ {
+				merged = append(merged, b[(j+k)%len(b)])
+			}
+
+			pts := make([]Point, len(merged))
+			for k, m := range merged {
+				pts[k] = p.Points[m]
+			}
+			if !isConvex(pts) {
+				return nil, false
+			}
+			return merged, true
+		}
+	}
+	return nil, false
+}
+
+// Kolizní tvary polygonu (konvexní rozklad)
+func (p Polygon) Colliders() ([]Collider, error) {
+	pieces, err := p.ConvexDecomposition()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Collider, len(pieces))
+	for i, piece := range pieces {
+		ret[i] = ConvexPolygon{Points: piece.Points}
+	}
+	return ret, nil
+}
+
+// Poměr délky špičky k vzdálenosti odsazení, nad kterým se ostrý roh seřízne
+const offsetMiterLimit = 2
+
+// Odsazení obvodu o delta (kladné zvětšuje, záporné zmenšuje)
+// Ostré rohy se seříznou; při zmenšení o víc, než je polygon široký, může výsledek protínat sám sebe
+func (p Polygon) Offset(delta float64) Polygon {
+	src := p.CounterClockwise().Points
+	if delta == 0 {
+		return Polygon{Points: src}
+	}
+
+	// Hrany nulové délky nemají normálu, zdvojené vrcholy se vynechají
+	pts := make([]Point, 0, len(src))
+	for i, v := range src {
+		if v != src[(i+1)%len(src)] {
+			pts = append(pts, v)
+		}
+	}
+	src = pts
+	n := len(src)
+	if n < 3 {
+		return Polygon{Points: src}
+	}
+
+	normal := func(a, b Point) Vector {
+		d := subPoints(b, a).Normalize()
+		return Vector{X: d.Y, Y: -d.X} // vnější normála u orientace proti směru hodinových ručiček
+	}
+
+	out := make([]Point, 0, n)
+	for i, v := range src {
+		n1 := normal(src[(i+n-1)%n], v)
+		n2 := normal(v, src[(i+1)%n])
+		miter := n1.Add(n2)
+		cos := miter.Length() / 2 // kosinus poloviny úhlu mezi normálami
+		// Seříznout lze jen roh, který se odsazením zostřuje (vypouklý při zvětšení, vydutý při zmenšení)
+		// Hrot o 180° (protilehlé normály) nemá špičku vůbec, ten se seřízne vždy
+		sharpens := (cross(src[(i+n-1)%n], v, src[(i+1)%n]) > 0) == (delta > 0)
+		if cos < collisionEpsilon || (sharpens && 1/cos > offsetMiterLimit) {
+			out = append(out, v.AddVector(n1.Scale(delta)), v.AddVector(n2.Scale(delta)))
+			continue
+		}
+		out = append(out, v.AddVector(miter.Normalize().Scale(delta/cos)))
+	}
+	return Polygon{Points: out}
+}
@@ -0,0 +1,414 @@
+package main
+
+import "math"
+
+// Množinové operace s polygony (Greiner-Hormann)
+// Vstupem jsou jednoduché polygony. Samotný algoritmus zvládá jen hrany, které se protínají uvnitř;
+// společné hrany a vrcholy ležící na hraně druhého polygonu (běžné u osově zarovnané geometrie)
+// se řeší malým rozšířením ořezového polygonu a přichycením výsledku zpět na původní vrcholy.
+// Výsledek bez průsečíků řeší vzájemné vnoření:
+// díra se vrací jako samostatný polygon s opačnou orientací (vnější obrys je proti směru hodinových ručiček)
+// Výsledkem je seznam polygonů, protože operace může polygon rozdělit na více částí
+
+type clipVertex struct {
+	p            Point
+	next, prev   *clipVertex
+	neighbor     *clipVertex // stejný průsečík v druhém polygonu
+	alpha        float64     // poloha průsečíku na hraně 0..1
+	intersection bool
+	entry        bool
+	visited      bool
+}
+
+// Kruhový seznam vrcholů polygonu
+func newClipList(pts []Point) *clipVertex {
+	var first, last *clipVertex
+	for _, p := range pts {
+		v := &clipVertex{p: p}
+		if first == nil {
+			first = v
+		} else {
+			last.next, v.prev = v, last
+		}
+		last = v
+	}
+	last.next, first.prev = first, last
+	return first
+}
+
+// Vložení průsečíku mezi vrcholy from a to podle alpha
+func insertIntersection(v, from, to *clipVertex) {
+	cur := from.next
+	for cur != to && cur.alpha < v.alpha {
+		cur = cur.next
+	}
+	v.next, v.prev = cur, cur.prev
+	cur.prev.next = v
+	cur.prev = v
+}
+
+// Další původní (ne průsečíkový) vrchol
+func nextOriginal(v *clipVertex) *clipVertex {
+	for v = v.next; v.intersection; v = v.next {
+	}
+	return v
+}
+
+// Průsečík úseček a1-a2 a b1-b2 uvnitř obou (bez koncových bodů)
+func segmentIntersection(a1, a2, b1, b2 Point) (alphaA, alphaB float64, ok bool) {
+	d := (b2.Y-b1.Y)*(a2.X-a1.X) - (b2.X-b1.X)*(a2.Y-a1.Y)
+	if d == 0 {
+		return 0, 0, false
+	}
+	alphaA = ((b2.X-b1.X)*(a1.Y-b1.Y) - (b2.Y-b1.Y)*(a1.X-b1.X)) / d
+	alphaB = ((a2.X-a1.X)*(a1.Y-b1.Y) - (a2.Y-a1.Y)*(a1.X-b1.X)) / d
+	const eps = 1e-12
+	if alphaA <= eps || alphaA >= 1-eps || alphaB <= eps || alphaB >= 1-eps {
+		return 0, 0, false
+	}
+	return alphaA, alphaB, true
+}
+
+// forwardS a forwardC určují operaci: průnik (true, true), sjednocení (false, false), rozdíl (false, true)
+func clipPolygons(subject, clip Polygon, forwardS, forwardC bool) []Polygon {
+	subject, clip = subject.CounterClockwise(), clip.CounterClockwise()
+
+	b := subject.Bounds().Union(clip.Bounds())
+	scale := math.Max(b.Width(), b.Height())
+	if scale == 0 {
+		return nil
+	}
+	eps := clipDegenerateEps * scale
+	if !clipDegenerate(subject, clip, eps) {
+		return greinerHormann(subject, clip, forwardS, forwardC)
+	}
+
+	// Rozšíření kolem těžiště (a případně posun), dokud vrcholy neleží na hranách druhého polygonu
+	delta := clipPerturbation * scale
+	center := clip.Centroid()
+	perturbed := clip
+	for _, shift := range clipShifts {
+		perturbed = Polygon{Points: make([]Point, len(clip.Points))}
+		for i, pt := range clip.Points {
+			d := subPoints(pt, center)
+			if l := d.Length(); l > 0 {
+				d = d.Scale(delta / l)
+			}
+			perturbed.Points[i] = pt.AddVector(d).AddVector(shift.Scale(delta))
+		}
+		if !clipDegenerate(subject, perturbed, eps) {
+			break
+		}
+	}
+
+	ret := greinerHormann(subject, perturbed, forwardS, forwardC)
+	return snapClipResult(ret, subject, clip, perturbed, 8*delta, delta*scale)
+}
+
+// Relativní tolerance, pod kterou vrchol leží na hraně druhého polygonu
+const clipDegenerateEps = 1e-9
+
+// Relativní velikost rozšíření ořezového polygonu při degenerovaném vstupu
+const clipPerturbation = 1e-7
+
+// Posuny zkoušené po rozšíření, pokud samotné rozšíření degeneraci neodstraní
+var clipShifts = []Vector{{}, {X: 0.7548776662, Y: 0.5698402910}, {X: -0.5698402910, Y: 0.7548776662}, {X: -0.7548776662, Y: -0.5698402910}}
+
+// Leží některý vrchol jednoho polygonu na hraně druhého?
+func clipDegenerate(a, b Polygon, eps float64) bool {
+	return verticesOnBoundary(a, b, eps) || verticesOnBoundary(b, a, eps)
+}
+
+func verticesOnBoundary(a, b Polygon, eps float64) bool {
+	for _, pt := range a.Points {
+		if onBoundary(pt, b, eps) {
+			return true
+		}
+	}
+	return false
+}
+
+// Vrácení výsledku z rozšířeného ořezového polygonu na původní geometrii:
+// vrcholy rozšířeného polygonu se vrátí na původní místo, body blízko původních vrcholů se na ně přichytí
+// a odstraní se zdvojené body, zpětné hroty a tenké zbytky s plochou pod minArea
+func snapClipResult(polys []Polygon, subject, clip, perturbed Polygon, tol, minArea float64) []Polygon {
+	originals := make(map[Point]Point, len(clip.Points))
+	for i, pt := range perturbed.Points {
+		originals[pt] = clip.Points[i]
+	}
+	vertices := append(append([]Point(nil), subject.Points...), clip.Points...)
+
+	var ret []Polygon
+	for _, p := range polys {
+		pts := make([]Point, 0, len(p.Points))
+		for _, pt := range p.Points {
+			if o, ok := originals[pt]; ok {
+				pt = o
+			} else {
+				for _, v := range vertices {
+					if subPoints(pt, v).Length() <= tol {
+						pt = v
+						break
+					}
+				}
+			}
+			pts = append(pts, pt)
+		}
+		pts = removeSpikes(pts)
+		if len(pts) >= 3 && math.Abs(Polygon{Points: pts}.SignedArea()) > minArea {
+			ret = append(ret, Polygon{Points: pts})
+		}
+	}
+	return orientHoles(ret)
+}
+
+// Odstranění zdvojených bodů a hrotů tam a zpět (a, b, a) v uzavřené lomené čáře
+func removeSpikes(pts []Point) []Point {
+	for {
+		n := len(pts)
+		if n < 3 {
+			return nil
+		}
+		removed := -1
+		for i := range pts {
+			prev, next := pts[(i+n-1)%n], pts[(i+1)%n]
+			// Zdvojený bod, nebo hrot; po odstranění hrotu se z prev a next stane zdvojený bod
+			if pts[i] == next || prev == next {
+				removed = i
+				break
+			}
+		}
+		if removed < 0 {
+			return pts
+		}
+		pts = append(pts[:removed], pts[removed+1:]...)
+	}
+}
+
+func greinerHormann(subject, clip Polygon, forwardS, forwardC bool) []Polygon {
+	s := newClipList(subject.Points)
+	c := newClipList(clip.Points)
+
+	// Fáze 1: vložení průsečíků do obou seznamů
+	found := false
+	for sv := s; ; {
+		sn := nextOriginal(sv)
+		for cv := c; ; {
+			cn := nextOriginal(cv)
+			if a, b, ok := segmentIntersection(sv.p, sn.p, cv.p, cn.p); ok {
+				p := sv.p.AddVector(subPoints(sn.p, sv.p).Scale(a))
+				is := &clipVertex{p: p, alpha: a, intersection: true}
+				ic := &clipVertex{p: p, alpha: b, intersection: true}
+				is.neighbor, ic.neighbor = ic, is
+				insertIntersection(is, sv, sn)
+				insertIntersection(ic, cv, cn)
+				found = true
+			}
+			if cv = cn; cv == c {
+				break
+			}
+		}
+		if sv = sn; sv == s {
+			break
+		}
+	}
+
+	if !found {
+		return clipNested(subject, clip, forwardS, forwardC)
+	}
+
+	// Fáze 2: označení vstupních a výstupních průsečíků
+	markEntries(s, clip, forwardS)
+	markEntries(c, subject, forwardC)
+
+	// Fáze 3: procházení po nenavštívených průsečících
+	var ret []Polygon
+	for {
+		start := firstUnvisited(s)
+		if start == nil {
+			break
+		}
+		var pts []Point
+		cur := start
+		pts = append(pts, cur.p)
+		for {
+			cur.visited, cur.neighbor.visited = true, true
+			forward := cur.entry
+			for {
+				if forward {
+					cur = cur.next
+				} else {
+					cur = cur.prev
+				}
+				pts = append(pts, cur.p)
+				if cur.intersection {
+					break
+				}
+			}
+			cur = cur.neighbor
+			if cur.visited {
+				break
+			}
+		}
+		if len(pts) > 1 && pts[len(pts)-1] == pts[0] {
+			pts = pts[:len(pts)-1]
+		}
+		if len(pts) >= 3 {
+			ret = append(ret, Polygon{Points: pts})
+		}
+	}
+	return orientHoles(ret)
+}
+
+// Obrysy proti směru hodinových ručiček, díry (polygony uvnitř jiného výsledku) po směru
+func orientHoles(polys []Polygon) []Polygon {
+	for i, p := range polys {
+		depth := 0
+		for j, o := range polys {
+			if i != j && containsAll(o, p.Points) {
+				depth++
+			}
+		}
+		polys[i] = p.CounterClockwise()
+		if depth%2 == 1 {
+			polys[i] = polys[i].Reverse()
+		}
+	}
+	return polys
+}
+
+func containsAll(p Polygon, pts []Point) bool {
+	for _, pt := range pts {
+		if !p.Contains(pt) {
+			return false
+		}
+	}
+	return true
+}
+
+func markEntries(list *clipVertex, other Polygon, forward bool) {
+	// První vrchol uvnitř druhého polygonu znamená, že první průsečík je výstupní
+	status := !other.Contains(list.p)
+	if !forward {
+		status = !status
+	}
+	for v := list; ; {
+		if v.intersection {
+			v.entry = status
+			status = !status
+		}
+		if v = v.next; v == list {
+			break
+		}
+	}
+}
+
+func firstUnvisited(list *clipVertex) *clipVertex {
+	for v := list; ; {
+		if v.intersection && !v.visited {
+			return v
+		}
+		if v = v.next; v == list {
+			return nil
+		}
+	}
+}
+
+// Polygony bez průsečíků hran: buď je jeden uvnitř druhého, nebo jsou disjunktní
+func clipNested(subject, clip Polygon, forwardS, forwardC bool) []Polygon {
+	b := subject.Bounds().Union(clip.Bounds())
+	eps := clipDegenerateEps * math.Max(b.Width(), b.Height())
+	subjectInClip := nestedIn(subject, clip, eps)
+	clipInSubject := nestedIn(clip, subject, eps)
+	// Shodné obrysy leží jeden v druhém; za vnořený se pak bere menší z nich
+	if subjectInClip && clipInSubject {
+		subjectInClip = subject.Area() <= clip.Area()
+		clipInSubject = !subjectInClip
+	}
+
+	switch {
+	case forwardS && forwardC: // průnik
+		if subjectInClip {
+			return []Polygon{subject}
+		}
+		if clipInSubject {
+			return []Polygon{clip}
+		}
+		return nil
+	case !forwardS && !forwardC: // sjednocení
+		if subjectInClip {
+			return []Polygon{clip}
+		}
+		if clipInSubject {
+			return []Polygon{subject}
+		}
+		return []Polygon{subject, clip}
+	default: // rozdíl
+		if subjectInClip {
+			return nil
+		}
+		if clipInSubject {
+			return []Polygon{subject, clip.Reverse()}
+		}
+		return []Polygon{subject}
+	}
+}
+
+// Leží polygon p uvnitř polygonu o? Hrany se nekříží, takže vnoření je všechno, nebo nic
+// a rozhoduje libovolný vrchol p, který neleží na hraně o (jinak střed hrany)
+func nestedIn(p, o Polygon, eps float64) bool {
+	for _, pt := range p.Points {
+		if !onBoundary(pt, o, eps) {
+			return o.Contains(pt)
+		}
+	}
+	for i, pt := range p.Points {
+		mid := lerpPoint(pt, p.Points[(i+1)%len(p.Points)], 0.5)
+		if !onBoundary(mid, o, eps) {
+			return o.Contains(mid)
+		}
+	}
+	// Celý obrys p leží na obrysu o
+	return true
+}
+
+func onBoundary(pt Point, p Polygon, eps float64) bool {
+	for i, q := range p.Points {
+		if (Segment{A: q, B: p.Points[(i+1)%len(p.Points)]}).Distance(pt) <= eps {
+			return true
+		}
+	}
+	return false
+}
+
+// Průnik dvou polygonů
+func (p Polygon) Intersection(o Polygon) []Polygon {
+	if len(p.Points) < 3 || len(o.Points) < 3 {
+		return nil
+	}
+	return clipPolygons(p, o, true, true)
+}
+
+// Sjednocení dvou polygonů
+func (p Polygon) Union(o Polygon) []Polygon {
+	switch {
+	case len(p.Points) < 3 && len(o.Points) < 3:
+		return nil
+	case len(p.Points) < 3:
+		return []Polygon{o.CounterClockwise()}
+	case len(o.Points) < 3:
+		return []Polygon{p.CounterClockwise()}
+	}
+	return clipPolygons(p, o, false, false)
+}
+
+// Rozdíl p - o
+func (p Polygon) Difference(o Polygon) []Polygon {
+	switch {
+	case len(p.Points) < 3:
+		return nil
+	case len(o.Points) < 3:
+		return []Polygon{p.CounterClockwise()}
+	}
+	return clipPolygons(p, o, false, true)
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// Zásah paprsku nebo taženého tvaru
+type RayHit struct {
+	Sprite   Spriter
+	Point    Point
+	Normal   Vector  // normála povrchu v místě zásahu (míří proti paprsku)
+	Distance float64 // vzdálenost od počátku ve směru paprsku
+}
+
+// Průsečík paprsku s tvarem; dir musí být jednotkový
+// Vrací vzdálenost a normálu, pokud paprsek tvar zasáhne do maxDist
+// Pokud počátek leží uvnitř tvaru, je zásah ve vzdálenosti 0
+func rayCollider(origin Point, dir Vector, maxDist float64, c Collider) (float64, Vector, bool) {
+	if circle, ok := c.(Circle); ok {
+		return rayCircle(origin, dir, maxDist, circle)
+	}
+	return rayPolygon(origin, dir, maxDist, colliderVertices(c))
+}
+
+func rayCircle(origin Point, dir Vector, maxDist float64, c Circle) (float64, Vector, bool) {
+	m := subPoints(origin, c.Center)
+	b := m.Dot(dir)
+	cc := m.Dot(m) - c.Radius*c.Radius
+	if cc <= 0 {
+		return 0, dir.Scale(-1), true
+	}
+	if b > 0 {
+		return 0, Vector{}, false // počátek je venku a paprsek míří pryč
+	}
+	disc := b*b - cc
+	if disc < 0 {
+		return 0, Vector{}, false
+	}
+
+	t := -b - math.Sqrt(disc)
+	if t > maxDist {
+		return 0, Vector{}, false
+	}
+	hit := origin.AddVector(dir.Scale(t))
+	return t, subPoints(hit, c.Center).Normalize(), true
+}
+
+// Cyrus-Beck ořezání paprsku konvexním polygonem
+func rayPolygon(origin Point, dir Vector, maxDist float64, poly []Point) (float64, Vector, bool) {
+	if len(poly) < 3 {
+		return 0, Vector{}, false
+	}
+
+	center := centroidOf(poly)
+	tEnter, tExit := 0.0, maxDist
+	var normal Vector
+	entered := false
+
+	for i := range poly {
+		p1, p2 := poly[i], poly[(i+1)%len(poly)]
+		n := subPoints(p2, p1).Perpendicular().Normalize()
+		if n.Length() == 0 {
+			continue
+		}
+		// Normála hrany musí mířit ven z polygonu
+		if subPoints(p1, center).Dot(n) < 0 {
+			n = n.Scale(-1)
+		}
+
+		num := subPoints(p1, origin).Dot(n)
+		den := dir.Dot(n)
+		if math.Abs(den) < collisionEpsilon {
+			if num < 0 {
+				return 0, Vector{}, false // paprsek je rovnoběžný s hranou a leží mimo
+			}
+			continue
+		}
+
+		t := num / den
+		if den < 0 {
+			if t > tEnter {
+				tEnter = t
+				normal = n
+				entered = true
+			}
+		} else if t < tExit {
+			tExit = t
+		}
+		if tEnter > tExit {
+			return 0, Vector{}, false
+		}
+	}
+
+	if !entered {
+		// Počátek leží uvnitř polygonu
+		return 0, dir.Scale(-1), true
+	}
+	return tEnter, normal, true
+}
+
+// Funkce pro sprity scény, které vyhovují masce, a jejich kolizní tvary v oblasti r
+func (s *Scene) castCandidates(r Rectangle, mask uint32, fn func(sp Spriter, c Collider)) {
+	for _, sp := range s.QueryRect(r) {
+		if sp.GetSprite().Body.Category&mask == 0 {
+			continue
+		}
+		fn(sp, sp.GetSprite().Collider())
+	}
+}
+
+func rayBounds(origin Point, dir Vector, maxDist float64) Rectangle {
+	end := origin.AddVector(dir.Scale(maxDist))
+	return BoundingBox([]Point{origin, end})
+}
+
+// Funkce vrací všechny zásahy paprsku seřazené podle vzdálenosti
+// Zasaženy mohou být jen sprity, jejichž kategorie je v masce
+func (s *Scene) RaycastAll(origin Point, dir Vector, maxDist float64, mask uint32) []RayHit {
+	dir = dir.Normalize()
+	if dir.Length() == 0 || maxDist <= 0 {
+		return nil
+	}
+
+	var hits []RayHit
+	s.castCandidates(rayBounds(origin, dir, maxDist), mask, func(sp Spriter, c Collider) {
+		if t, n, ok := rayCollider(origin, dir, maxDist, c); ok {
+			hits = append(hits, RayHit{
+				Sprite:   sp,
+				Point:    origin.AddVector(dir.Scale(t)),
+				Normal:   n,
+				Distance: t,
+			})
+		}
+	})
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Distance < hits[j].Distance
+	})
+	return hits
+}
+
+// Funkce vrací první zásah paprsku (line of sight, střely, míření myší)
+func (s *Scene) Raycast(origin Point, dir Vector, maxDist float64, mask uint32) (RayHit, bool) {
+	hits := s.RaycastAll(origin, dir, maxDist, mask)
+	if len(hits) == 0 {
+		return RayHit{}, false
+	}
+	return hits[0], true
+}
+
+// Počet kroků bisekce při hledání okamžiku dotyku taženého tvaru
+const shapeCastRefine = 24
+
+// Tažení tvaru po přímce proti jednomu tvaru c; dir musí být jednotkový
+// Vrací poslední volnou vzdálenost, vzdálenost prvního dotyku a kontakt v místě dotyku
+func castShape(shape Collider, dir Vector, maxDist float64, c Collider) (free, touch float64, m Manifold, ok bool) {
+	at := func(t float64) (Manifold, bool) {
+		return CollideShapes(shape.Transform(dir.Scale(t), nil), c)
+	}
+
+	// Krok vzorkování je menší než tvar, aby nepřeskočil tenké překážky stejné velikosti
+	b := shape.Bounds()
+	step := math.Min(b.Max.X-b.Min.X, b.Max.Y-b.Min.Y) / 2
+	if step <= 0 {
+		step = 1
+	}
+
+	// Hledání prvního vzorku s průnikem
+	prev := 0.0
+	t := 0.0
+	m, hit := at(0)
+	for !hit && t < maxDist {
+		prev = t
+		t = math.Min(t+step, maxDist)
+		m, hit = at(t)
+	}
+	if !hit {
+		return 0, 0, Manifold{}, false
+	}
+	if t == 0 {
+		return 0, 0, m, true
+	}
+
+	// Zpřesnění bisekcí mezi posledním volným a prvním kolidujícím vzorkem
+	lo, hi := prev, t
+	for i := 0; i < shapeCastRefine; i++ {
+		mid := (lo + hi) / 2
+		if mm, ok := at(mid); ok {
+			hi, m = mid, mm
+		} else {
+			lo = mid
+		}
+	}
+	return lo, hi, m, true
+}
+
+// Funkce pro tažení tvaru po přímce; vrací první sprite, do kterého tvar narazí
+// Sprite ignore (např. ten, kterému tvar patří) se přeskočí; může být nil
+// Vzdálenost v RayHit je poslední poloha, ve které se tvar ještě nedotýká
+func (s *Scene) ShapeCast(shape Collider, dir Vector, maxDist float64, mask uint32, ignore Spriter) (RayHit, bool) {
+	return s.ShapeCastFunc(shape, dir, maxDist, func(sp Spriter) bool {
+		return sp != ignore && sp.GetSprite().Body.Category&mask != 0
+	})
+}
+
+// Stejné jako ShapeCast, ale o tom, které sprity se testují, rozhoduje filtr
+func (s *Scene) ShapeCastFunc(shape Collider, dir Vector, maxDist float64, filter func(Spriter) bool) (RayHit, bool) {
+	dir = dir.Normalize()
+	if dir.Length() == 0 || maxDist < 0 {
+		return RayHit{}, false
+	}
+
+	b := shape.Bounds()
+	swept := BoundingBox([]Point{b.Min, b.Max, b.Min.AddVector(dir.Scale(maxDist)), b.Max.AddVector(dir.Scale(maxDist))})
+
+	best := RayHit{Distance: math.Inf(1)}
+	s.castCandidates(swept, MaskAll, func(sp Spriter, c Collider) {
+		if filter != nil && !filter(sp) {
+			return
+		}
+
+		free, _, m, ok := castShape(shape, dir, math.Min(maxDist, best.Distance), c)
+		if ok && free < best.Distance {
+			var pt Point
+			if len(m.Points) > 0 {
+				pt = m.Points[0]
+			}
+			best = RayHit{Sprite: sp, Point: pt, Normal: m.Normal.Scale(-1), Distance: free}
+		}
+	})
+
+	if best.Sprite == nil {
+		return RayHit{}, false
+	}
+	return best, true
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Osa pro dělení obdélníku
+type Axis int
+
+const (
+	Horizontal Axis = iota // části vedle sebe
+	Vertical               // části pod sebou
+)
+
+// Kotva jako relativní bod obdélníku: 0,0 je levý horní roh, 1,1 pravý dolní
+type Anchor struct {
+	X, Y float64
+}
+
+var (
+	AnchorTopLeft     = Anchor{0, 0}
+	AnchorTop         = Anchor{0.5, 0}
+	AnchorTopRight    = Anchor{1, 0}
+	AnchorLeft        = Anchor{0, 0.5}
+	AnchorCenter      = Anchor{0.5, 0.5}
+	AnchorRight       = Anchor{1, 0.5}
+	AnchorBottomLeft  = Anchor{0, 1}
+	AnchorBottom      = Anchor{0.5, 1}
+	AnchorBottomRight = Anchor{1, 1}
+)
+
+// Obdélník z polohy a velikosti
+func RectFromSize(x, y, w, h float64) Rectangle {
+	return Rectangle{Min: Point{X: x, Y: y}, Max: Point{X: x + w, Y: y + h}}.Canon()
+}
+
+// Obdélník s Min vlevo nahoře a Max vpravo dole
+func (r Rectangle) Canon() Rectangle {
+	return Rectangle{
+		Min: Point{X: math.Min(r.Min.X, r.Max.X), Y: math.Min(r.Min.Y, r.Max.Y)},
+		Max: Point{X: math.Max(r.Min.X, r.Max.X), Y: math.Max(r.Min.Y, r.Max.Y)},
+	}
+}
+
+func (r Rectangle) Width() float64 {
+	return r.Max.X - r.Min.X
+}
+
+func (r Rectangle) Height() float64 {
+	return r.Max.Y - r.Min.Y
+}
+
+func (r Rectangle) Size() Vector {
+	return Vector{X: r.Width(), Y: r.Height()}
+}
+
+func (r Rectangle) Center() Point {
+	return Point{X: (r.Min.X + r.Max.X) / 2, Y: (r.Min.Y + r.Max.Y) / 2}
+}
+
+// Obdélník nulové nebo záporné plochy
+func (r Rectangle) Empty() bool {
+	return r.Min.X >= r.Max.X || r.Min.Y >= r.Max.Y
+}
+
+// Bod obdélníku daný kotvou
+func (r Rectangle) AnchorPoint(a Anchor) Point {
+	return Point{X: r.Min.X + a.X*r.Width(), Y: r.Min.Y + a.Y*r.Height()}
+}
+
+func (r Rectangle) Translate(v Vector) Rectangle {
+	return Rectangle{Min: r.Min.AddVector(v), Max: r.Max.AddVector(v)}
+}
+
+// Společná část dvou obdélníků; false, pokud se nepřekrývají
+func (r Rectangle) Intersection(o Rectangle) (Rectangle, bool) {
+	ret := Rectangle{
+		Min: Point{X: math.Max(r.Min.X, o.Min.X), Y: math.Max(r.Min.Y, o.Min.Y)},
+		Max: Point{X: math.Min(r.Max.X, o.Max.X), Y: math.Min(r.Max.Y, o.Max.Y)},
+	}
+	if ret.Empty() {
+		return Rectangle{}, false
+	}
+	return ret, true
+}
+
+// Nejmenší obdélník obsahující oba obdélníky
+func (r Rectangle) Union(o Rectangle) Rectangle {
+	return Rectangle{
+		Min: Point{X: math.Min(r.Min.X, o.Min.X), Y: math.Min(r.Min.Y, o.Min.Y)},
+		Max: Point{X: math.Max(r.Max.X, o.Max.X), Y: math.Max(r.Max.Y, o.Max.Y)},
+	}
+}
+
+// Zmenšení o dx zleva i zprava a dy shora i zdola; příliš velké zmenšení skončí ve středu
+func (r Rectangle) Inset(dx, dy float64) Rectangle {
+	c := r.Center()
+	return Rectangle{
+		Min: Point{X: math.Min(r.Min.X+dx, c.X), Y: math.Min(r.Min.Y+dy, c.Y)},
+		Max: Point{X: math.Max(r.Max.X-dx, c.X), Y: math.Max(r.Max.Y-dy, c.Y)},
+	}
+}
+
+// Zvětšení o dx vlevo i vpravo a dy nahoře i dole
+func (r Rectangle) Expand(dx, dy float64) Rectangle {
+	return r.Inset(-dx, -dy)
+}
+
+// Rozdělení v poměru t (0..1) podél osy; vrací levou/horní a pravou/dolní část
+func (r Rectangle) Split(axis Axis, t float64) (Rectangle, Rectangle) {
+	t = math.Max(0, math.Min(t, 1))
+	a, b := r, r
+	if axis == Horizontal {
+		x := r.Min.X + t*r.Width()
+		a.Max.X, b.Min.X = x, x
+	} else {
+		y := r.Min.Y + t*r.Height()
+		a.Max.Y, b.Min.Y = y, y
+	}
+	return a, b
+}
+
+// Umístění obdélníku (jeho velikosti) do rodiče tak, aby se kryly body dané kotvou
+// (např. AnchorBottomRight přilepí obdélník do pravého dolního rohu rodiče)
+func (r Rectangle) AnchorIn(parent Rectangle, a Anchor) Rectangle {
+	target := parent.AnchorPoint(a)
+	return r.Translate(subPoints(target, r.AnchorPoint(a)))
+}
+
+// Převod na celočíselný sdl.Rect se zaokrouhlením hran, takže sousední obdélníky na sebe navazují
+func (r Rectangle) ToNativeRounded() sdl.Rect {
+	minX, minY := math.Round(r.Min.X), math.Round(r.Min.Y)
+	maxX, maxY := math.Round(r.Max.X), math.Round(r.Max.Y)
+	return sdl.Rect{X: int32(minX), Y: int32(minY), W: int32(maxX - minX), H: int32(maxY - minY)}
+}
+
+// Převod na nejmenší celočíselný sdl.Rect, který obdélník celý pokryje (ořez, culling)
+func (r Rectangle) ToNativeOuter() sdl.Rect {
+	minX, minY := math.Floor(r.Min.X), math.Floor(r.Min.Y)
+	maxX, maxY := math.Ceil(r.Max.X), math.Ceil(r.Max.Y)
+	return sdl.Rect{X: int32(minX), Y: int32(minY), W: int32(maxX - minX), H: int32(maxY - minY)}
+}
+
+// Převod na sdl.FRect bez zaokrouhlení
+func (r Rectangle) ToNativeF() sdl.FRect {
+	return sdl.FRect{X: float32(r.Min.X), Y: float32(r.Min.Y), W: float32(r.Width()), H: float32(r.Height())}
+}
+
+// Převod ze sdl.Rect
+func RectFromNative(r sdl.Rect) Rectangle {
+	return RectFromSize(float64(r.X), float64(r.Y), float64(r.W), float64(r.H))
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+)
+
+// Hlavička souboru s nahrávkou vstupů
+var replayMagic = [4]byte{'C', 'L', 'R', 'P'}
+
+const replayVersion = 1
+
+// Značka konce nahrávky v místě akce
+const replayEnd = -1
+
+// Nahraný vstup: akce s číslem ticku, před kterým byla aplikována
+type ReplayEvent struct {
+	Tick uint64
+	InputEvent
+}
+
+// Záznamník vstupních událostí
+// Formát: hlavička (magic, verze, seed, tick rate), pak záznamy
+// (uvarint rozdíl ticků, varint akce, uvarint hodnota) a nakonec záznam s akcí replayEnd
+type Recorder struct {
+	w        *bufio.Writer
+	lastTick uint64
+	buf      [3 * binary.MaxVarintLen64]byte
+	err      error
+}
+
+func NewRecorder(w io.Writer, seed uint64, tickRate int) (*Recorder, error) {
+	r := &Recorder{w: bufio.NewWriter(w)}
+
+	r.w.Write(replayMagic[:])
+	r.w.WriteByte(replayVersion)
+	n := binary.PutUvarint(r.buf[:], seed)
+	n += binary.PutUvarint(r.buf[n:], uint64(tickRate))
+	if _, err := r.w.Write(r.buf[:n]); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Hodnota se ukládá přesně (bity float64), s otočeným pořadím bajtů, aby obvyklé hodnoty
+// jako 0, 1 nebo -1 zabraly jen pár bajtů
+func encodeReplayValue(v float64) uint64 {
+	return bits.ReverseBytes64(math.Float64bits(v))
+}
+
+func decodeReplayValue(u uint64) float64 {
+	return math.Float64frombits(bits.ReverseBytes64(u))
+}
+
+func (r *Recorder) Record(tick uint64, ev InputEvent) {
+	r.write(tick, int64(ev.Action), encodeReplayValue(ev.Value))
+}
+
+func (r *Recorder) write(tick uint64, action int64, value uint64) {
+	if r.err != nil {
+		return
+	}
+	if tick < r.lastTick {
+		r.err = fmt.Errorf("replay tick %d goes back in time", tick)
+		return
+	}
+
+	n := binary.PutUvarint(r.buf[:], tick-r.lastTick)
+	n += binary.PutVarint(r.buf[n:], action)
+	n += binary.PutUvarint(r.buf[n:], value)
+	_, r.err = r.w.Write(r.buf[:n])
+	r.lastTick = tick
+}
+
+// Funkce pro ukončení nahrávky; tick je délka nahrané session
+func (r *Recorder) Close(tick uint64) error {
+	r.write(tick, replayEnd, 0)
+	if r.err != nil {
+		return r.err
+	}
+	return r.w.Flush()
+}
+
+// Načtená nahrávka připravená k přehrání
+type Replay struct {
+	Seed     uint64
+	TickRate int
+	Length   uint64 // počet ticků nahrané session
+	Events   []ReplayEvent
+	next     int
+}
+
+// Funkce pro načtení celé nahrávky do paměti
+func LoadReplay(rd io.Reader) (*Replay, error) {
+	br := bufio.NewReader(rd)
+
+	var magic [4]byte
+	if _, err := io.ReadFull(br, magic[:]); err != nil {
+		return nil, err
+	}
+	if magic != replayMagic {
+		return nil, errors.New("not a replay file")
+	}
+	version, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if version != replayVersion {
+		return nil, fmt.Errorf("unsupported replay version %d", version)
+	}
+
+	seed, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	rp := &Replay{Seed: seed, TickRate: int(rate)}
+	tick := uint64(0)
+	for {
+		delta, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("truncated replay: %w", err)
+		}
+		action, err := binary.ReadVarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("truncated replay: %w", err)
+		}
+		value, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("truncated replay: %w", err)
+		}
+
+		tick += delta
+		if action == replayEnd {
+			rp.Length = tick
+			return rp, nil
+		}
+		rp.Events = append(rp.Events, ReplayEvent{
+			Tick:       tick,
+			InputEvent: InputEvent{Action: Action(action), Value: decodeReplayValue(value)},
+		})
+	}
+}
+
+// Funkce vrací události, které se mají aplikovat před daným tickem
+func (rp *Replay) EventsFor(tick uint64) []ReplayEvent {
+	start := rp.next
+	for rp.next < len(rp.Events) && rp.Events[rp.next].Tick <= tick {
+		rp.next++
+	}
+	return rp.Events[start:rp.next]
+}
+
+func (rp *Replay) Done(tick uint64) bool {
+	return tick >= rp.Length
+}
+
+// Přetočení nahrávky na začátek
+func (rp *Replay) Rewind() {
+	rp.next = 0
+}
+
+// Funkce pro posun nahrávky tak, aby další vrácené události patřily k danému ticku
+func (rp *Replay) Seek(tick uint64) {
+	rp.next = 0
+	for rp.next < len(rp.Events) && rp.Events[rp.next].Tick < tick {
+		rp.next++
+	}
+}
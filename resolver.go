@@ -0,0 +1,152 @@
+package main
+
+import "math"
+
+// Výchozí počet iterací řešiče kolizí
+const DefaultIterations = 8
+
+const (
+	// Průnik, který se toleruje bez korekce polohy (zabraňuje chvění těl v klidu)
+	penetrationSlop = 0.01
+	// Podíl průniku, který se opraví v jednom kroku
+	positionCorrection = 0.8
+	// Pod touto rychlostí nárazu se neuplatní pružnost (tělesa v klidu neposkakují);
+	// práh se navíc zvětšuje podle rychlosti, kterou těla naberou gravitací za krok
+	restitutionThreshold = 1.0
+)
+
+// Kontakt dvou těl připravený pro řešič
+type contactConstraint struct {
+	A, B     Spriter
+	Manifold Manifold
+
+	restitution float64
+	friction    float64
+	target      float64 // požadovaná rychlost ve směru normály po odrazu
+	tangent     Vector  // pevná tečna kontaktu, třecí impulz podél ní může mít obě znaménka
+	normalImp   float64 // akumulovaný impulz ve směru normály
+	tangentImp  float64 // akumulovaný třecí impulz podél tangent
+}
+
+func mixMaterials(a, b Material) (restitution, friction float64) {
+	return math.Max(a.Restitution, b.Restitution), math.Sqrt(a.Friction * b.Friction)
+}
+
+func (p *Physics) collectContacts(dt float64) {
+	threshold := math.Max(restitutionThreshold, 2*p.Gravity.Length()*dt)
+
+	p.contacts = p.contacts[:0]
+	for _, t := range p.touches {
+		sa, sb := t.A.GetSprite(), t.B.GetSprite()
+		if sa.Body.Sensor || sb.Body.Sensor {
+			continue // triggery se jen hlásí
+		}
+		if sa.Body.InvMass()+sb.Body.InvMass() == 0 {
+			continue // dvě nehybná nebo kinematická těla se neřeší
+		}
+		if !oneWayBlocks(sa, sb, t.Manifold.Normal, dt) || !oneWayBlocks(sb, sa, t.Manifold.Normal.Scale(-1), dt) {
+			continue
+		}
+
+		c := contactConstraint{A: t.A, B: t.B, Manifold: t.Manifold, tangent: t.Manifold.Normal.Perpendicular()}
+		c.restitution, c.friction = mixMaterials(sa.Body.Material, sb.Body.Material)
+
+		vn := sb.Movement.Add(sa.Movement.Scale(-1)).Dot(t.Manifold.Normal)
+		if vn < -threshold {
+			c.target = -c.restitution * vn
+		}
+		p.contacts = append(p.contacts, c)
+	}
+}
+
+// Jednosměrná plošina p zastaví těleso o jen tehdy, když normála n (od p k o) míří nahoru
+// a spodní hrana o byla na začátku kroku nad horní hranou plošiny
+func oneWayBlocks(p, o *Sprite, n Vector, dt float64) bool {
+	if !p.Body.OneWay {
+		return true
+	}
+	if n.Y >= 0 {
+		return false
+	}
+	bottom := o.Collider().Bounds().Max.Y - o.Movement.Y*dt
+	return bottom <= p.Collider().Bounds().Min.Y-p.Movement.Y*dt+penetrationSlop
+}
+
+// Impulzní řešič: iterativně aplikuje impulzy ve směru normály a tečny (tření) a impulzy
+// spojení, pak těla rozdělí korekcí polohy
+func (p *Physics) resolveCollisions(dt float64) {
+	p.collectContacts(dt)
+	if len(p.contacts) == 0 && len(p.Joints) == 0 {
+		return
+	}
+
+	iterations := p.Iterations
+	if iterations < 1 {
+		iterations = 1
+	}
+	for i := 0; i < iterations; i++ {
+		for k := range p.contacts {
+			p.contacts[k].solveVelocity()
+		}
+		for _, j := range p.Joints {
+			j.SolveVelocity()
+		}
+	}
+
+	for _, j := range p.Joints {
+		j.SolvePosition()
+	}
+	for k := range p.contacts {
+		c := &p.contacts[k]
+		if c.correctPosition() {
+			p.Index.Update(c.A, c.A.GetSprite().indexBounds())
+			p.Index.Update(c.B, c.B.GetSprite().indexBounds())
+		}
+	}
+}
+
+func (c *contactConstraint) solveVelocity() {
+	a, b := c.A.GetSprite(), c.B.GetSprite()
+	ia, ib := a.Body.InvMass(), b.Body.InvMass()
+	k := ia + ib
+	n := c.Manifold.Normal
+
+	// Impulz ve směru normály; akumulovaný impulz nesmí být záporný (těla se nepřitahují)
+	vr := b.Movement.Add(a.Movement.Scale(-1))
+	dj := (c.target - vr.Dot(n)) / k
+	old := c.normalImp
+	c.normalImp = math.Max(old+dj, 0)
+	dj = c.normalImp - old
+	applyPair(a, b, n.Scale(dj))
+
+	// Tření podle Coulombova zákona, omezené normálovým impulzem
+	t := c.tangent
+	vr = b.Movement.Add(a.Movement.Scale(-1))
+	jt := -vr.Dot(t) / k
+	maxFriction := c.friction * c.normalImp
+	old = c.tangentImp
+	c.tangentImp = math.Max(-maxFriction, math.Min(old+jt, maxFriction))
+	jt = c.tangentImp - old
+	applyPair(a, b, t.Scale(jt))
+}
+
+// Impulz j působí na b, opačný impulz na a
+func applyPair(a, b *Sprite, j Vector) {
+	a.ApplyImpulse(j.Scale(-1))
+	b.ApplyImpulse(j)
+}
+
+// Posune těla od sebe podle hmotnosti; vrací true, pokud došlo k posunu
+func (c *contactConstraint) correctPosition() bool {
+	a, b := c.A.GetSprite(), c.B.GetSprite()
+	ia, ib := a.Body.InvMass(), b.Body.InvMass()
+
+	depth := c.Manifold.Depth - penetrationSlop
+	if depth <= 0 {
+		return false
+	}
+	corr := c.Manifold.Normal.Scale(depth * positionCorrection / (ia + ib))
+	a.Translate(corr.Scale(-ia))
+	b.Translate(corr.Scale(ib))
+	return true
+}
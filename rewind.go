@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+)
+
+// Rozhraní pro sprity, jejichž stav lze uložit do snapshotu a zpětně obnovit
+// Sprite ho implementuje pro transformaci a rychlost; struktury, které Sprite vkládají,
+// mohou SaveState/LoadState přepsat a přidat vlastní stav
+type Snapshotter interface {
+	SaveState() any
+	LoadState(any)
+}
+
+// Uložený stav jednoho spritu
+type SpriteState struct {
+	Rect          Rectangle
+	Movement      Vector
+	Accelleration Vector
+	Matrix        Affine2D
+}
+
+// Snapshot celé scény v daném ticku
+type Snapshot struct {
+	Tick     uint64
+	Rand     Rand
+	Layers   map[string]layerSnapshot
+	Input    InputState         // hodnoty akcí na začátku ticku (podržené klávesy při přehrávání)
+	touching map[spritePair]int // dotyky z minulého kroku, aby obnova nevyvolala falešné enter/exit
+}
+
+type layerSnapshot struct {
+	Sprites []Spriter // seznam spritů vrstvy (přidané/odebrané sprity se při obnově vrátí)
+	States  []any     // stav každého spritu, nil pokud sprite neimplementuje Snapshotter
+}
+
+// Kruhový buffer snapshotů scény pro přetáčení simulace
+type Rewinder struct {
+	Interval  int // snapshot se pořizuje každých Interval ticků
+	snapshots []Snapshot
+	start     int
+	count     int
+}
+
+func NewRewinder(capacity, interval int) *Rewinder {
+	if capacity < 1 {
+		capacity = 1
+	}
+	if interval < 1 {
+		interval = 1
+	}
+	return &Rewinder{
+		Interval:  interval,
+		snapshots: make([]Snapshot, capacity),
+	}
+}
+
+func (s *Sprite) SaveState() any {
+	return SpriteState{
+		Rect:          s.Rect,
+		Movement:      s.Movement,
+		Accelleration: s.Accelleration,
+		Matrix:        s.Matrix,
+	}
+}
+
+func (s *Sprite) LoadState(state any) {
+	st, ok := state.(SpriteState)
+	if !ok {
+		return
+	}
+	s.Rect = st.Rect
+	s.Movement = st.Movement
+	s.Accelleration = st.Accelleration
+	s.Matrix = st.Matrix
+}
+
+// Funkce pro pořízení snapshotu, pokud na daný tick připadá
+// Vstup může být nil, pak se jeho stav neukládá
+func (rw *Rewinder) Capture(tick uint64, scene *Scene, input *Input) {
+	if tick%uint64(rw.Interval) != 0 {
+		return
+	}
+
+	snap := Snapshot{
+		Tick:   tick,
+		Rand:   *scene.Rand,
+		Layers: make(map[string]layerSnapshot, len(scene.Layers)),
+	}
+	for name, l := range scene.Layers {
+		ls := layerSnapshot{
+			Sprites: append([]Spriter(nil), l.Sprites...),
+			States:  make([]any, len(l.Sprites)),
+		}
+		for i, sp := range l.Sprites {
+			if ss, ok := sp.(Snapshotter); ok {
+				ls.States[i] = ss.SaveState()
+			}
+		}
+		snap.Layers[name] = ls
+	}
+	if input != nil {
+		snap.Input = input.SaveState()
+	}
+	if scene.Physics != nil {
+		snap.touching = maps.Clone(scene.Physics.touching)
+	}
+
+	// Po přetočení může přijít tick, který už v bufferu je - novější historii zahodíme
+	if tick == 0 {
+		rw.Clear()
+	} else {
+		rw.DiscardAfter(tick - 1)
+	}
+
+	if rw.count < len(rw.snapshots) {
+		rw.snapshots[(rw.start+rw.count)%len(rw.snapshots)] = snap
+		rw.count++
+	} else {
+		// Buffer je plný, přepíšeme nejstarší snapshot
+		rw.snapshots[rw.start] = snap
+		rw.start = (rw.start + 1) % len(rw.snapshots)
+	}
+}
+
+func (rw *Rewinder) at(i int) *Snapshot {
+	return &rw.snapshots[(rw.start+i)%len(rw.snapshots)]
+}
+
+func (rw *Rewinder) Len() int {
+	return rw.count
+}
+
+// Rozsah ticků, na které se lze přetočit
+func (rw *Rewinder) Range() (oldest, newest uint64, ok bool) {
+	if rw.count == 0 {
+		return 0, 0, false
+	}
+	return rw.at(0).Tick, rw.at(rw.count - 1).Tick, true
+}
+
+// Funkce pro nalezení nejnovějšího snapshotu, který není novější než daný tick
+func (rw *Rewinder) Find(tick uint64) (*Snapshot, error) {
+	for i := rw.count - 1; i >= 0; i-- {
+		if snap := rw.at(i); snap.Tick <= tick {
+			return snap, nil
+		}
+	}
+	return nil, fmt.Errorf("no snapshot at or before tick %d", tick)
+}
+
+// Funkce pro obnovení scény ze snapshotu; vrací tick, na který se scéna vrátila
+// Stav vstupu se obnoví jen tehdy, když input není nil (při přehrávání; živý vstup odpovídá zařízením)
+func (rw *Rewinder) Restore(tick uint64, scene *Scene, input *Input) (uint64, error) {
+	snap, err := rw.Find(tick)
+	if err != nil {
+		return 0, err
+	}
+
+	*scene.Rand = snap.Rand
+	for name, ls := range snap.Layers {
+		l, exists := scene.Layers[name]
+		if !exists {
+			continue
+		}
+		l.Sprites = append(l.Sprites[:0:0], ls.Sprites...)
+		for i, sp := range ls.Sprites {
+			if ls.States[i] == nil {
+				continue
+			}
+			if ss, ok := sp.(Snapshotter); ok {
+				ss.LoadState(ls.States[i])
+			}
+		}
+	}
+	if input != nil {
+		input.LoadState(snap.Input)
+	}
+	if scene.Physics != nil {
+		scene.Physics.touching = maps.Clone(snap.touching)
+		if scene.Physics.touching == nil {
+			scene.Physics.touching = make(map[spritePair]int)
+		}
+	}
+
+	return snap.Tick, nil
+}
+
+// Funkce pro zahození snapshotů novějších než daný tick (při pokračování z přetočeného stavu)
+func (rw *Rewinder) DiscardAfter(tick uint64) {
+	for rw.count > 0 && rw.at(rw.count-1).Tick > tick {
+		*rw.at(rw.count - 1) = Snapshot{}
+		rw.count--
+	}
+}
+
+func (rw *Rewinder) Clear() {
+	for i := range rw.snapshots {
+		rw.snapshots[i] = Snapshot{}
+	}
+	rw.start = 0
+	rw.count = 0
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/veandco/go-sdl2/sdl"
 )
@@ -11,6 +13,9 @@ type Scene struct {
 	Order    []string // Udržuje pořadí vrstev podle názvu
 	Clear    Drawer
 	Renderer *sdl.Renderer
+	Rand     *Rand     // Seedovaný generátor scény - pro deterministické přehrávání používejte jen tento
+	Physics  *Physics  // nil = scéna bez fyziky
+	View     Rectangle // viditelná oblast scény; sprity mimo ni se nevykreslují
 }
 
 func NewScene(wnd *sdl.Window) Scene {
@@ -25,7 +30,9 @@ func NewScene(wnd *sdl.Window) Scene {
 		Layers:   make(map[string]*Layer),
 		Order:    []string{},
 		Clear:    FillDraw{Dst: Rectangle{Max: Point{X: float64(width), Y: float64(height)}}},
+		View:     Rectangle{Max: Point{X: float64(width), Y: float64(height)}},
 		Renderer: r,
+		Rand:     NewRand(uint64(time.Now().UnixNano())),
 	}
 
 	return ret
@@ -108,3 +115,83 @@ func (s *Scene) Evaluate() error {
 	}
 	return nil
 }
+
+// Funkce pro nastavení seedu generátoru náhodných čísel scény
+func (s *Scene) Reseed(seed uint64) {
+	s.Rand.Reseed(seed)
+}
+
+func (s *Scene) Draw() error {
+	if s.Clear != nil {
+		if err := s.Clear.Draw(s.Renderer); err != nil {
+			return err
+		}
+	}
+
+	for _, l := range s.IterateLayersInOrder() {
+		if err := l.DrawVisible(s.Renderer, s.visibleFilter()); err != nil {
+			return err
+		}
+	}
+
+	s.Renderer.Present()
+	return nil
+}
+
+// Filtr viditelných spritů pro ořezání při vykreslování (nil = kreslit vše)
+// Rozhoduje vykreslovaný (transformovaný) obdélník spritu, ne kolizní tvar v indexu,
+// který může být menší než obrázek
+func (s *Scene) visibleFilter() func(Spriter) bool {
+	if s.View == (Rectangle{}) {
+		return nil
+	}
+	return func(sp Spriter) bool {
+		return overlaps(sp.GetSprite().Bounds(), s.View)
+	}
+}
+
+// Funkce pro nalezení spritů, jejichž bounding box zasahuje do obdélníku
+func (s *Scene) QueryRect(r Rectangle) []Spriter {
+	if s.Physics != nil {
+		return s.Physics.Index.QueryRect(r)
+	}
+
+	var ret []Spriter
+	for _, l := range s.IterateLayersInOrder() {
+		for _, sp := range l.Sprites {
+			if overlaps(sp.GetSprite().Collider().Bounds(), r) {
+				ret = append(ret, sp)
+			}
+		}
+	}
+	return ret
+}
+
+// Funkce pro výběr spritů pod bodem (např. pod kurzorem myši)
+// Testuje se vykreslovaný obdélník spritu včetně transformace Matrix; výsledek je v pořadí vrstev
+// S fyzikou se kandidáti berou z broad-phase indexu (poloha z posledního kroku), jinak se prochází celá scéna
+func (s *Scene) QueryPoint(p Point) []Spriter {
+	var ret []Spriter
+	if s.Physics != nil {
+		// Kandidáti z indexu podle obalového obdélníku, přesný test až podle transformace
+		for _, sp := range s.Physics.Index.QueryPoint(p) {
+			if sp.GetSprite().ContainsPoint(p) {
+				ret = append(ret, sp)
+			}
+		}
+		// Index pořadí nezachovává, seřadíme podle pořadí ve scéně z posledního kroku
+		sort.SliceStable(ret, func(i, j int) bool {
+			return s.Physics.order[ret[i]] < s.Physics.order[ret[j]]
+		})
+		return ret
+	}
+
+	for _, l := range s.IterateLayersInOrder() {
+		for _, sp := range l.Sprites {
+			if sp.GetSprite().ContainsPoint(p) {
+				ret = append(ret, sp)
+			}
+		}
+	}
+	return ret
+}
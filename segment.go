@@ -0,0 +1,185 @@
+package main
+
+import "math"
+
+// Úsečka mezi body A a B
+type Segment struct {
+	A, B Point
+}
+
+// Polopřímka z Origin ve směru Dir (jednotkový vektor, viz NewRay)
+type Ray struct {
+	Origin Point
+	Dir    Vector
+}
+
+func NewRay(origin Point, dir Vector) Ray {
+	return Ray{Origin: origin, Dir: dir.Normalize()}
+}
+
+func (s Segment) Vector() Vector {
+	return subPoints(s.B, s.A)
+}
+
+func (s Segment) Length() float64 {
+	return s.Vector().Length()
+}
+
+func (s Segment) Midpoint() Point {
+	return Point{X: (s.A.X + s.B.X) / 2, Y: (s.A.Y + s.B.Y) / 2}
+}
+
+// Jednotková normála (směr úsečky otočený o 90°)
+func (s Segment) Normal() Vector {
+	return s.Vector().Perpendicular().Normalize()
+}
+
+func (s Segment) Bounds() Rectangle {
+	return BoundingBox([]Point{s.A, s.B})
+}
+
+// Parametr kolmého průmětu bodu na přímku úsečky (0 = A, 1 = B, mimo úsečku i mimo 0..1)
+func (s Segment) Project(p Point) float64 {
+	d := s.Vector()
+	l2 := d.Dot(d)
+	if l2 == 0 {
+		return 0
+	}
+	return subPoints(p, s.A).Dot(d) / l2
+}
+
+func (s Segment) At(t float64) Point {
+	return s.A.AddVector(s.Vector().Scale(t))
+}
+
+// Nejbližší bod úsečky k bodu p
+func (s Segment) ClosestPoint(p Point) Point {
+	return s.At(math.Max(0, math.Min(s.Project(p), 1)))
+}
+
+func (s Segment) Distance(p Point) float64 {
+	return subPoints(p, s.ClosestPoint(p)).Length()
+}
+
+// Průsečík dvou úseček; rovnoběžné (i překrývající se) úsečky průsečík nemají
+func (s Segment) Intersect(o Segment) (Point, bool) {
+	t, u, ok := lineIntersection(s.A, s.Vector(), o.A, o.Vector())
+	if !ok || t < 0 || t > 1 || u < 0 || u > 1 {
+		return Point{}, false
+	}
+	return s.At(t), true
+}
+
+// Průsečík přímek p + t*r a q + u*s; vrací parametry t a u
+func lineIntersection(p Point, r Vector, q Point, s Vector) (t, u float64, ok bool) {
+	rxs := r.X*s.Y - r.Y*s.X
+	if math.Abs(rxs) < collisionEpsilon {
+		return 0, 0, false
+	}
+	qp := subPoints(q, p)
+	t = (qp.X*s.Y - qp.Y*s.X) / rxs
+	u = (qp.X*r.Y - qp.Y*r.X) / rxs
+	return t, u, true
+}
+
+// Část úsečky uvnitř obdélníku (Liang-Barsky); false, pokud úsečka obdélník mine
+func (s Segment) ClipRect(r Rectangle) (Segment, bool) {
+	t0, t1, ok := clipLine(s.A, s.Vector(), 0, 1, r)
+	if !ok {
+		return Segment{}, false
+	}
+	return Segment{A: s.At(t0), B: s.At(t1)}, true
+}
+
+// Úsečka protíná obdélník nebo v něm leží
+func (s Segment) IntersectsRect(r Rectangle) bool {
+	_, ok := s.ClipRect(r)
+	return ok
+}
+
+// Ořez parametru přímky p + t*d na interval, ve kterém leží v obdélníku
+func clipLine(p Point, d Vector, t0, t1 float64, r Rectangle) (float64, float64, bool) {
+	checks := [4][2]float64{
+		{-d.X, p.X - r.Min.X},
+		{d.X, r.Max.X - p.X},
+		{-d.Y, p.Y - r.Min.Y},
+		{d.Y, r.Max.Y - p.Y},
+	}
+	for _, c := range checks {
+		den, num := c[0], c[1]
+		if den == 0 {
+			if num < 0 {
+				return 0, 0, false
+			}
+			continue
+		}
+		t := num / den
+		if den < 0 {
+			t0 = math.Max(t0, t)
+		} else {
+			t1 = math.Min(t1, t)
+		}
+		if t0 > t1 {
+			return 0, 0, false
+		}
+	}
+	return t0, t1, true
+}
+
+func (r Ray) At(t float64) Point {
+	return r.Origin.AddVector(r.Dir.Scale(t))
+}
+
+// Vzdálenost průmětu bodu na paprsek (záporná za počátkem)
+func (r Ray) Project(p Point) float64 {
+	return subPoints(p, r.Origin).Dot(r.Dir)
+}
+
+func (r Ray) ClosestPoint(p Point) Point {
+	return r.At(math.Max(0, r.Project(p)))
+}
+
+func (r Ray) Distance(p Point) float64 {
+	return subPoints(p, r.ClosestPoint(p)).Length()
+}
+
+// Průsečík paprsku s úsečkou; vrací vzdálenost od počátku
+func (r Ray) IntersectSegment(s Segment) (float64, bool) {
+	t, u, ok := lineIntersection(r.Origin, r.Dir, s.A, s.Vector())
+	if !ok || t < 0 || u < 0 || u > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+// Vstup paprsku do obdélníku; počátek uvnitř obdélníku je zásah ve vzdálenosti 0
+func (r Ray) IntersectRect(rect Rectangle) (float64, bool) {
+	t0, _, ok := clipLine(r.Origin, r.Dir, 0, math.Inf(1), rect)
+	return t0, ok
+}
+
+// První průsečík paprsku s obvodem polygonu (i nekonvexního) a normála hrany proti paprsku
+// Na rozdíl od Scene.Raycast hledá hranu i tehdy, když počátek leží uvnitř polygonu
+func (r Ray) IntersectPolygon(p Polygon) (float64, Vector, bool) {
+	best := math.Inf(1)
+	var normal Vector
+	for i, a := range p.Points {
+		edge := Segment{A: a, B: p.Points[(i+1)%len(p.Points)]}
+		if t, ok := r.IntersectSegment(edge); ok && t < best {
+			best = t
+			normal = edge.Normal()
+			if normal.Dot(r.Dir) > 0 {
+				normal = normal.Scale(-1)
+			}
+		}
+	}
+	if math.IsInf(best, 1) {
+		return 0, Vector{}, false
+	}
+	return best, normal, true
+}
+
+// Zásah kolizního tvaru paprskem do vzdálenosti maxDist (jako Scene.Raycast pro jeden tvar)
+func (r Ray) IntersectCollider(c Collider, maxDist float64) (float64, Vector, bool) {
+	return rayCollider(r.Origin, r.Dir, maxDist, c)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"math"
 
 	"github.com/veandco/go-sdl2/sdl"
@@ -17,8 +18,9 @@ type Rectangle struct {
 	Max Point
 }
 
+// Převod na sdl.Rect; hrany se zaokrouhlují (viz ToNativeRounded)
 func (r Rectangle) ToNative() sdl.Rect {
-	return sdl.Rect{X: int32(r.Min.X), Y: int32(r.Min.Y), W: int32(r.Max.X - r.Min.X), H: int32(r.Max.Y - r.Min.Y)}
+	return r.ToNativeRounded()
 }
 
 // Funkce pro kontrolu, zda je bod uvnitř obdélníku
@@ -195,7 +197,7 @@ func RadiansToDegrees(radians float64) float64 {
 
 // Funkce pro převod stupňů na radiány
 func DegreesToRadians(degrees float64) float64 {
-	return degrees * (3.141592653589793 / math.Pi)
+	return degrees * math.Pi / 180
 }
 
 type Matrix struct {
@@ -206,54 +208,39 @@ type Matrix struct {
 
 // Konstruktor pro vytvoření matice
 func NewMatrix(rows, columns int) *Matrix {
+	// Řádky sdílejí jedno pole, aby matice stála jen dvě alokace
+	backing := make([]float64, rows*columns)
 	data := make([][]float64, rows)
 	for i := range data {
-		data[i] = make([]float64, columns)
+		data[i] = backing[i*columns : (i+1)*columns : (i+1)*columns]
 	}
 	return &Matrix{Rows: rows, Columns: columns, Data: data}
 }
 
-// Metoda pro sčítání dvou matic
-func (m1 *Matrix) Add(m2 *Matrix) *Matrix {
-	if m1.Rows != m2.Rows || m1.Columns != m2.Columns {
-		return nil // nebo můžeš vrátit chybu
-	}
-	result := NewMatrix(m1.Rows, m1.Columns)
-	for i := 0; i < m1.Rows; i++ {
-		for j := 0; j < m1.Columns; j++ {
-			result.Data[i][j] = m1.Data[i][j] + m2.Data[i][j]
-		}
+// Metoda pro vytvoření nezávislé kopie matice
+func (m *Matrix) Clone() *Matrix {
+	result := NewMatrix(m.Rows, m.Columns)
+	for i := range m.Data {
+		copy(result.Data[i], m.Data[i])
 	}
 	return result
 }
 
-// Metoda pro odčítání dvou matic
+// Metoda pro sčítání dvou matic (při nesouhlasu rozměrů vrací nil, viz verze s chybou)
+func (m1 *Matrix) Add(m2 *Matrix) *Matrix {
+	result, _ := m1.AddChecked(m2)
+	return result
+}
+
+// Metoda pro odčítání dvou matic (při nesouhlasu rozměrů vrací nil, viz verze s chybou)
 func (m1 *Matrix) Subtract(m2 *Matrix) *Matrix {
-	if m1.Rows != m2.Rows || m1.Columns != m2.Columns {
-		return nil // nebo můžeš vrátit chybu
-	}
-	result := NewMatrix(m1.Rows, m1.Columns)
-	for i := 0; i < m1.Rows; i++ {
-		for j := 0; j < m1.Columns; j++ {
-			result.Data[i][j] = m1.Data[i][j] - m2.Data[i][j]
-		}
-	}
+	result, _ := m1.SubtractChecked(m2)
 	return result
 }
 
-// Metoda pro násobení matice s jinou maticí
+// Metoda pro násobení matice s jinou maticí (při nesouhlasu rozměrů vrací nil, viz verze s chybou)
 func (m1 *Matrix) Multiply(m2 *Matrix) *Matrix {
-	if m1.Columns != m2.Rows {
-		return nil // nebo můžeš vrátit chybu
-	}
-	result := NewMatrix(m1.Rows, m2.Columns)
-	for i := 0; i < m1.Rows; i++ {
-		for j := 0; j < m2.Columns; j++ {
-			for k := 0; k < m1.Columns; k++ {
-				result.Data[i][j] += m1.Data[i][k] * m2.Data[k][j]
-			}
-		}
-	}
+	result, _ := m1.MultiplyChecked(m2)
 	return result
 }
 
@@ -315,75 +302,10 @@ func ScalingMatrix(sX, sY float64) *Matrix {
 	return result
 }
 
-// Metoda pro výpis matice
-func (m *Matrix) Print() {
-	for i := 0; i < m.Rows; i++ {
-		for j := 0; j < m.Columns; j++ {
-			fmt.Printf("%f ", m.Data[i][j])
-		}
-		fmt.Println()
-	}
-}
-
-// Funkce pro výpočet inverzní matice pomocí Gaussovy eliminace
-func (m *Matrix) Inverse() (*Matrix, error) {
-	if m.Rows != m.Columns {
-		return nil, fmt.Errorf("matice musí být čtvercová")
-	}
-
-	// Vytvoření rozšířené matice (m, I)
-	augmented := NewMatrix(m.Rows, 2*m.Columns)
-	for i := 0; i < m.Rows; i++ {
-		for j := 0; j < m.Columns; j++ {
-			augmented.Data[i][j] = m.Data[i][j]
-		}
-		augmented.Data[i][i+m.Columns] = 1 // Jednotková matice na pravé straně
-	}
-
-	// Gaussova eliminace
-	for i := 0; i < m.Rows; i++ {
-		// Hledání maximálního prvku v aktuálním sloupci
-		maxRow := i
-		for k := i + 1; k < m.Rows; k++ {
-			if math.Abs(augmented.Data[k][i]) > math.Abs(augmented.Data[maxRow][i]) {
-				maxRow = k
-			}
-		}
-
-		// Výměna maximálního řádku s aktuálním řádkem
-		if maxRow != i {
-			augmented.Data[i], augmented.Data[maxRow] = augmented.Data[maxRow], augmented.Data[i]
-		}
-
-		// Normalizace aktuálního řádku
-		divisor := augmented.Data[i][i]
-		if divisor == 0 {
-			return nil, fmt.Errorf("matice nemá inverzi")
-		}
-		for j := 0; j < 2*m.Columns; j++ {
-			augmented.Data[i][j] /= divisor
-		}
-
-		// Eliminuje ostatní řádky
-		for k := 0; k < m.Rows; k++ {
-			if k != i {
-				factor := augmented.Data[k][i]
-				for j := 0; j < 2*m.Columns; j++ {
-					augmented.Data[k][j] -= factor * augmented.Data[i][j]
-				}
-			}
-		}
-	}
-
-	// Extrakce inverzní matice
-	inverse := NewMatrix(m.Rows, m.Columns)
-	for i := 0; i < m.Rows; i++ {
-		for j := 0; j < m.Columns; j++ {
-			inverse.Data[i][j] = augmented.Data[i][j+m.Columns]
-		}
-	}
-
-	return inverse, nil
+// Metoda pro výpis matice do w (např. os.Stdout nebo log); pro text samotný viz String
+func (m *Matrix) Print(w io.Writer) error {
+	_, err := fmt.Fprintln(w, m.String())
+	return err
 }
 
 // Funkce pro výpočet přechodové matice mezi dvěma maticemi
@@ -399,8 +321,21 @@ func TransitionMatrix(from, to *Matrix) (*Matrix, error) {
 		return nil, err
 	}
 
-	transition := inverseFrom.Multiply(to)
-	return transition, nil
+	return inverseFrom.MultiplyChecked(to)
+}
+
+// Funkce pro plynulý přechod mezi dvěma afinními maticemi 3x3 (t v intervalu 0..1)
+// Matice se rozloží na posun, otočení, měřítko a zkosení, viz InterpolateAffine
+func InterpolateMatrix(from, to *Matrix, t float64) (*Matrix, error) {
+	a, err := AffineFromMatrix(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := AffineFromMatrix(to)
+	if err != nil {
+		return nil, err
+	}
+	return InterpolateAffine(a, b, t).Matrix(), nil
 }
 
 func TransformPoint(p Point, m *Matrix) Point {
@@ -7,10 +7,12 @@ type Sprite struct {
 	Rect          Rectangle // Velikost spritu (bounding box)
 	Movement      Vector
 	Accelleration Vector
-	Matrix        *Matrix // Transformace spritu
-	Texture       any     // Todo attach a texture
+	Matrix        Affine2D // Transformace spritu
+	Texture       any      // Todo attach a texture
 	Audio         any
 	D             Drawer
+	Body          Body     // Fyzikální vlastnosti spritu
+	Shape         Collider // Kolizní tvar relativně k Rect.Min, nil = celý Rect
 }
 
 type Spriter interface {
@@ -19,8 +21,11 @@ type Spriter interface {
 	Destroy()
 	ApplyPhysics(float32)
 	Draw(*sdl.Renderer) error // draw itself to a render target
+	GetSprite() *Sprite       // základní Sprite (u struktur, které Sprite vkládají)
 }
 
+var _ Spriter = (*Sprite)(nil)
+
 // Funkce pro vytvoření nového spritu
 func NewSprite(width, height float64) *Sprite {
 	s := Sprite{
@@ -28,12 +33,17 @@ func NewSprite(width, height float64) *Sprite {
 			Min: Point{X: 0, Y: 0},
 			Max: Point{X: width, Y: height},
 		},
-		Matrix: IdentityMatrix(3),
+		Matrix: IdentityAffine(),
+		Body:   NewBody(),
 	}
 
 	return &s
 }
 
+func (s *Sprite) GetSprite() *Sprite {
+	return s
+}
+
 func (s *Sprite) Tick() {
 	// stub
 }
@@ -42,10 +52,68 @@ func (s *Sprite) Transform() {
 	// stub
 }
 
+// Kolizní tvar spritu ve světových souřadnicích (včetně transformace Matrix)
+// Nulová matice (sprite vytvořený bez NewSprite) se bere jako žádná transformace
+func (s *Sprite) Collider() Collider {
+	m := s.transform()
+	if s.Shape != nil {
+		return s.Shape.Transform(Vector{X: s.Rect.Min.X, Y: s.Rect.Min.Y}, m)
+	}
+	return s.Rect.Polygon().Transform(Vector{}, m)
+}
+
+// Matice spritu, nebo nil, pokud sprite transformovaný není
+func (s *Sprite) transform() *Affine2D {
+	if s.Matrix == (Affine2D{}) || s.Matrix.IsIdentity() {
+		return nil
+	}
+	return &s.Matrix
+}
+
+// Rohy Rect po transformaci maticí Matrix (levý horní, pravý horní, pravý dolní, levý dolní)
+func (s *Sprite) OrientedBox() [4]Point {
+	r := s.Rect
+	ret := [4]Point{r.Min, {X: r.Max.X, Y: r.Min.Y}, r.Max, {X: r.Min.X, Y: r.Max.Y}}
+	if m := s.transform(); m != nil {
+		for i, p := range ret {
+			ret[i] = m.TransformPoint(p)
+		}
+	}
+	return ret
+}
+
+// Nejmenší osově zarovnaný obdélník obsahující transformovaný sprite
+func (s *Sprite) Bounds() Rectangle {
+	box := s.OrientedBox()
+	return BoundingBox(box[:])
+}
+
+// Obdélník, pod kterým je sprite v broad-phase indexu
+// Zahrnuje kolizní tvar i vykreslovaný obdélník, aby index posloužil pro kolize i pro výběr myší
+func (s *Sprite) indexBounds() Rectangle {
+	return s.Collider().Bounds().Union(s.Bounds())
+}
+
+// Leží bod uvnitř transformovaného spritu (výběr myší)
+func (s *Sprite) ContainsPoint(p Point) bool {
+	if m := s.transform(); m != nil {
+		inv, err := m.Inverse()
+		if err != nil {
+			return false // degenerovaná matice zploštila sprite na úsečku nebo bod
+		}
+		p = inv.TransformPoint(p)
+	}
+	return s.Rect.Contains(p)
+}
+
+// Funkce pro výpočet kontaktu s jiným spritem (normála míří od s k ss)
+func (s *Sprite) Contact(ss *Sprite) (Manifold, bool) {
+	return CollideShapes(s.Collider(), ss.Collider())
+}
+
 func (s *Sprite) Collide(ss *Sprite) []Point {
-	b, pts := s.Rect.Intersects(ss.Rect)
-	if b {
-		return pts
+	if m, ok := s.Contact(ss); ok {
+		return m.Points
 	}
 
 	return nil
@@ -55,15 +123,43 @@ func (s *Sprite) Destroy() {
 	//stub
 }
 
-func (s *Sprite) ApplyPhysics(dt float64) {
-	// todo better physics
-	s.Movement.X += dt * s.Accelleration.X
-	s.Movement.Y += dt * s.Accelleration.Y
+// Posun spritu o vektor se zachováním velikosti
+func (s *Sprite) Translate(dv Vector) {
+	s.Rect.Min = s.Rect.Min.AddVector(dv)
+	s.Rect.Max = s.Rect.Max.AddVector(dv)
+}
 
-	dv := s.Movement.Scale(dt)
+// Semi-implicitní Euler: nejdřív se aktualizuje rychlost, pak se s novou rychlostí posune pozice
+func (s *Sprite) ApplyPhysics(dt float32) {
+	h := float64(dt)
+	b := &s.Body
 
-	s.Rect.Min = s.Rect.Min.AddVector(dv)
-	s.Rect.Max = s.Rect.Min.AddVector(dv)
+	switch b.Mode {
+	case Static:
+		b.Force = Vector{}
+		return
+	case Dynamic:
+		a := s.Accelleration.Add(b.Force.Scale(b.InvMass()))
+		s.Movement = s.Movement.Add(a.Scale(h))
+
+		if b.Damping > 0 {
+			s.Movement = s.Movement.Scale(1 / (1 + h*b.Damping))
+		}
+	}
+	// Kinematické tělo se pohybuje jen podle Movement, které mu nastaví hra
+
+	if b.MaxSpeed > 0 {
+		if speed := s.Movement.Length(); speed > b.MaxSpeed {
+			s.Movement = s.Movement.Scale(b.MaxSpeed / speed)
+		}
+	}
+	b.Force = Vector{}
+
+	dv := s.Movement.Scale(h)
+	if b.CCD && b.sweep != nil {
+		dv = b.sweep(dv)
+	}
+	s.Translate(dv)
 }
 
 func (s *Sprite) Draw(r *sdl.Renderer) error {
@@ -0,0 +1,276 @@
+package main
+
+import "math"
+
+// Steering behaviors (Reynolds) pro autonomní sprity
+// Každé chování vrací požadované zrychlení; výsledky se vážně sečtou přes Blend
+// a výsledek se předá do Sprite.Accelleration pomocí Apply
+type SteeringAgent struct {
+	Sprite       *Sprite
+	MaxSpeed     float64 // rychlost, kterou chování požadují
+	MaxAccel     float64 // omezení výsledného zrychlení
+	ReactionTime float64 // za jak dlouho se má rychlost přiblížit požadované (s)
+
+	WanderDistance float64 // vzdálenost kružnice bloudění před agentem
+	WanderRadius   float64 // poloměr kružnice bloudění
+	WanderJitter   float64 // maximální změna úhlu bloudění za jedno volání (rad)
+
+	wanderAngle float64
+}
+
+// Vážený příspěvek jednoho chování
+type WeightedSteering struct {
+	Weight float64
+	Accel  Vector
+}
+
+// Cesta, po které agent jde (např. výsledek FindPath)
+type SteeringPath struct {
+	Points []Point
+	Radius float64 // jak blízko bodu se musí agent dostat, aby pokračoval k dalšímu
+	Loop   bool
+
+	index int
+}
+
+func NewSteeringAgent(s *Sprite, maxSpeed, maxAccel float64) *SteeringAgent {
+	// Rychlost omezí fyzika; limit, který už tělu nastavil někdo jiný, zůstane
+	if s.Body.MaxSpeed == 0 {
+		s.Body.MaxSpeed = maxSpeed
+	}
+	return &SteeringAgent{
+		Sprite:         s,
+		MaxSpeed:       maxSpeed,
+		MaxAccel:       maxAccel,
+		ReactionTime:   0.25,
+		WanderDistance: 40,
+		WanderRadius:   20,
+		WanderJitter:   0.5,
+	}
+}
+
+func (a *SteeringAgent) Position() Point {
+	return a.Sprite.Rect.Center()
+}
+
+func (a *SteeringAgent) Velocity() Vector {
+	return a.Sprite.Movement
+}
+
+// Směr pohybu; stojící agent se dívá doprava
+func (a *SteeringAgent) Heading() Vector {
+	if v := a.Velocity(); v.Length() > collisionEpsilon {
+		return v.Normalize()
+	}
+	return Vector{X: 1}
+}
+
+// Omezení délky vektoru
+func truncate(v Vector, max float64) Vector {
+	if l := v.Length(); l > max && l > 0 {
+		return v.Scale(max / l)
+	}
+	return v
+}
+
+// Zrychlení, které změní aktuální rychlost na požadovanou
+func (a *SteeringAgent) steerTowards(desired Vector) Vector {
+	accel := desired.Add(a.Velocity().Scale(-1))
+	if a.ReactionTime > 0 {
+		accel = accel.Scale(1 / a.ReactionTime)
+	}
+	return truncate(accel, a.MaxAccel)
+}
+
+// Přímo k cíli plnou rychlostí
+func (a *SteeringAgent) Seek(target Point) Vector {
+	return a.steerTowards(subPoints(target, a.Position()).Normalize().Scale(a.MaxSpeed))
+}
+
+// Pryč od bodu; ve vzdálenosti nad panicDistance agent neutíká (0 = vždy)
+func (a *SteeringAgent) Flee(target Point, panicDistance float64) Vector {
+	away := subPoints(a.Position(), target)
+	if panicDistance > 0 && away.Length() > panicDistance {
+		return Vector{}
+	}
+	return a.steerTowards(away.Normalize().Scale(a.MaxSpeed))
+}
+
+// K cíli se zpomalením uvnitř slowRadius, v cíli agent zastaví
+func (a *SteeringAgent) Arrive(target Point, slowRadius float64) Vector {
+	d := subPoints(target, a.Position())
+	dist := d.Length()
+	if dist < collisionEpsilon {
+		return a.steerTowards(Vector{})
+	}
+	speed := a.MaxSpeed
+	if dist < slowRadius {
+		speed *= dist / slowRadius
+	}
+	return a.steerTowards(d.Scale(speed / dist))
+}
+
+// Odhad, kde bude cíl, až k němu agent doběhne
+func (a *SteeringAgent) predict(target *Sprite) Point {
+	pos := target.Rect.Center()
+	dist := subPoints(pos, a.Position()).Length()
+	t := 0.0
+	if speed := a.MaxSpeed + target.Movement.Length(); speed > 0 {
+		t = dist / speed
+	}
+	return pos.AddVector(target.Movement.Scale(t))
+}
+
+// Pronásledování pohybujícího se cíle (míří na jeho odhadovanou polohu)
+func (a *SteeringAgent) Pursue(target *Sprite) Vector {
+	return a.Seek(a.predict(target))
+}
+
+// Útěk před pohybujícím se pronásledovatelem
+func (a *SteeringAgent) Evade(target *Sprite, panicDistance float64) Vector {
+	return a.Flee(a.predict(target), panicDistance)
+}
+
+// Náhodné bloudění: cíl se posouvá po kružnici před agentem
+// Generátor by měl být Scene.Rand, aby bylo bloudění deterministické pro replay
+func (a *SteeringAgent) Wander(r *Rand) Vector {
+	a.wanderAngle += r.Range(-a.WanderJitter, a.WanderJitter)
+	h := a.Heading()
+	center := a.Position().AddVector(h.Scale(a.WanderDistance))
+	offset := Vector{X: math.Cos(a.wanderAngle), Y: math.Sin(a.wanderAngle)}.Scale(a.WanderRadius)
+	return a.Seek(center.AddVector(offset))
+}
+
+// Chůze po cestě; na posledním bodě agent zastaví (nebo začne znovu, pokud je Loop)
+func (a *SteeringAgent) FollowPath(p *SteeringPath) Vector {
+	if len(p.Points) == 0 {
+		return a.steerTowards(Vector{})
+	}
+	last := len(p.Points) - 1
+	for i := 0; i <= last && (p.Loop || p.index < last); i++ {
+		if subPoints(p.Points[p.index], a.Position()).Length() > p.Radius {
+			break
+		}
+		p.index = (p.index + 1) % len(p.Points)
+	}
+	if p.index == last && !p.Loop {
+		return a.Arrive(p.Points[last], math.Max(p.Radius, a.MaxSpeed*a.ReactionTime))
+	}
+	return a.Seek(p.Points[p.index])
+}
+
+// Agent došel na konec cesty
+func (p *SteeringPath) Done() bool {
+	return !p.Loop && p.index >= len(p.Points)-1
+}
+
+// Aktuální cílový bod cesty
+func (p *SteeringPath) Current() (Point, bool) {
+	if len(p.Points) == 0 {
+		return Point{}, false
+	}
+	return p.Points[min(p.index, len(p.Points)-1)], true
+}
+
+func (p *SteeringPath) Reset() {
+	p.index = 0
+}
+
+// Vyhýbání se překážkám: tvar agenta se protáhne ve směru pohybu do vzdálenosti lookAhead
+// (úměrné rychlosti) a při zásahu se agent odkloní podél normály překážky
+func (a *SteeringAgent) AvoidObstacles(scene *Scene, lookAhead float64, mask uint32) Vector {
+	speed := a.Velocity().Length()
+	if speed < collisionEpsilon || a.MaxSpeed <= 0 {
+		return Vector{}
+	}
+	dist := lookAhead * math.Min(speed/a.MaxSpeed, 1)
+	h := a.Heading()
+
+	hit, ok := scene.ShapeCast(a.Sprite.Collider(), h, dist, mask, a.Sprite)
+	if !ok {
+		return Vector{}
+	}
+
+	// Boční složka normály; při čelním nárazu se uhne kolmo ke směru pohybu
+	side := hit.Normal.Add(h.Scale(-hit.Normal.Dot(h)))
+	if side.Length() < collisionEpsilon {
+		side = h.Perpendicular()
+	}
+	urgency := 1 - hit.Distance/math.Max(dist, collisionEpsilon)
+	return side.Normalize().Scale(a.MaxAccel * urgency)
+}
+
+// Sousední sprity v okruhu radius (pro hejna), bez agenta samotného
+func (a *SteeringAgent) Neighbors(scene *Scene, radius float64) []*Sprite {
+	pos := a.Position()
+	area := Rectangle{
+		Min: Point{X: pos.X - radius, Y: pos.Y - radius},
+		Max: Point{X: pos.X + radius, Y: pos.Y + radius},
+	}
+
+	var ret []*Sprite
+	for _, sp := range scene.QueryRect(area) {
+		s := sp.GetSprite()
+		if s != a.Sprite && subPoints(s.Rect.Center(), pos).Length() <= radius {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+// Oddělení: odpuzování od sousedů, silnější čím jsou blíž
+func (a *SteeringAgent) Separation(neighbors []*Sprite) Vector {
+	pos := a.Position()
+	var push Vector
+	for _, n := range neighbors {
+		away := subPoints(pos, n.Rect.Center())
+		if d := away.Length(); d > collisionEpsilon {
+			push = push.Add(away.Scale(1 / (d * d)))
+		}
+	}
+	if push.Length() < collisionEpsilon {
+		return Vector{}
+	}
+	return a.steerTowards(push.Normalize().Scale(a.MaxSpeed))
+}
+
+// Zarovnání: přizpůsobení směru pohybu průměru sousedů
+func (a *SteeringAgent) Alignment(neighbors []*Sprite) Vector {
+	if len(neighbors) == 0 {
+		return Vector{}
+	}
+	var avg Vector
+	for _, n := range neighbors {
+		avg = avg.Add(n.Movement)
+	}
+	if avg.Length() < collisionEpsilon {
+		return Vector{}
+	}
+	return a.steerTowards(avg.Normalize().Scale(a.MaxSpeed))
+}
+
+// Soudržnost: pohyb ke středu sousedů
+func (a *SteeringAgent) Cohesion(neighbors []*Sprite) Vector {
+	if len(neighbors) == 0 {
+		return Vector{}
+	}
+	centers := make([]Point, len(neighbors))
+	for i, n := range neighbors {
+		centers[i] = n.Rect.Center()
+	}
+	return a.Seek(centroidOf(centers))
+}
+
+// Vážený součet chování omezený na MaxAccel
+func (a *SteeringAgent) Blend(behaviors ...WeightedSteering) Vector {
+	var sum Vector
+	for _, b := range behaviors {
+		sum = sum.Add(b.Accel.Scale(b.Weight))
+	}
+	return truncate(sum, a.MaxAccel)
+}
+
+// Nastavení výsledného zrychlení spritu; rychlost omezí Body.MaxSpeed (nastavené v NewSteeringAgent)
+func (a *SteeringAgent) Apply(accel Vector) {
+	a.Sprite.Accelleration = truncate(accel, a.MaxAccel)
+}
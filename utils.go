@@ -1 +1,198 @@
 package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Deterministický generátor náhodných čísel (splitmix64)
+// Na rozdíl od math/rand je posloupnost pevně daná algoritmem, takže se nemění mezi verzemi Go
+// a replay nahraný na jednom stroji se přehraje stejně i na jiném
+type Rand struct {
+	seed  uint64
+	state uint64
+}
+
+func NewRand(seed uint64) *Rand {
+	return &Rand{seed: seed, state: seed}
+}
+
+func (r *Rand) Seed() uint64 {
+	return r.seed
+}
+
+// Funkce pro návrat generátoru do počátečního stavu s novým seedem
+func (r *Rand) Reseed(seed uint64) {
+	r.seed = seed
+	r.state = seed
+}
+
+func (r *Rand) Uint64() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Náhodné číslo v intervalu [0, 1)
+func (r *Rand) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
+
+// Náhodné celé číslo v intervalu [0, n)
+func (r *Rand) Intn(n int) int {
+	if n <= 0 {
+		panic("Intn: n musí být kladné")
+	}
+	return int(r.Uint64() % uint64(n))
+}
+
+// Náhodné číslo v intervalu [min, max)
+func (r *Rand) Range(min, max float64) float64 {
+	return min + r.Float64()*(max-min)
+}
+
+// Náhodné celé číslo v intervalu [min, max)
+func (r *Rand) IntRange(min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + r.Intn(max-min)
+}
+
+func (r *Rand) Bool() bool {
+	return r.Uint64()&1 == 1
+}
+
+// Jev s pravděpodobností p
+func (r *Rand) Chance(p float64) bool {
+	return r.Float64() < p
+}
+
+// Normální rozdělení se středem 0 a směrodatnou odchylkou 1 (Box-Muller)
+func (r *Rand) NormFloat64() float64 {
+	u := 1 - r.Float64() // (0, 1], aby logaritmus nebyl nekonečný
+	return math.Sqrt(-2*math.Log(u)) * math.Cos(2*math.Pi*r.Float64())
+}
+
+// Nezávislý generátor odvozený z tohoto (např. pro částice, které nemají ovlivnit zbytek simulace)
+func (r *Rand) Split() *Rand {
+	return NewRand(r.Uint64())
+}
+
+// Náhodná permutace čísel 0..n-1
+func (r *Rand) Perm(n int) []int {
+	ret := make([]int, n)
+	for i := range ret {
+		ret[i] = i
+	}
+	Shuffle(r, ret)
+	return ret
+}
+
+// Index vybraný s pravděpodobností úměrnou váze; záporné váhy se berou jako nulové
+// Vrací -1, pokud je součet vah nulový
+func (r *Rand) WeightedIndex(weights []float64) int {
+	total := 0.0
+	for _, w := range weights {
+		total += math.Max(w, 0)
+	}
+	if total <= 0 {
+		return -1
+	}
+
+	x := r.Float64() * total
+	last := -1
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		if x -= w; x < 0 {
+			return i
+		}
+		last = i
+	}
+	return last // zaokrouhlovací chyba na konci součtu
+}
+
+// Funkce pro náhodné zamíchání (Fisher-Yates)
+func Shuffle[T any](r *Rand, items []T) {
+	for i := len(items) - 1; i > 0; i-- {
+		j := r.Intn(i + 1)
+		items[i], items[j] = items[j], items[i]
+	}
+}
+
+// Funkce pro náhodný výběr prvku; prázdný seznam vrací nulovou hodnotu
+func Pick[T any](r *Rand, items []T) T {
+	var zero T
+	if len(items) == 0 {
+		return zero
+	}
+	return items[r.Intn(len(items))]
+}
+
+// Funkce pro výběr prvku s pravděpodobností úměrnou váze (weights[i] patří k items[i])
+func WeightedPick[T any](r *Rand, items []T, weights []float64) (T, bool) {
+	var zero T
+	i := r.WeightedIndex(weights[:min(len(items), len(weights))])
+	if i < 0 {
+		return zero, false
+	}
+	return items[i], true
+}
+
+// Náhodný úhel v radiánech [0, 2π)
+func (r *Rand) Angle() float64 {
+	return r.Float64() * 2 * math.Pi
+}
+
+// Náhodný jednotkový vektor
+func (r *Rand) UnitVector() Vector {
+	a := r.Angle()
+	return Vector{X: math.Cos(a), Y: math.Sin(a)}
+}
+
+// Rovnoměrně náhodný bod v obdélníku
+func (r *Rand) PointInRect(rect Rectangle) Point {
+	return Point{X: r.Range(rect.Min.X, rect.Max.X), Y: r.Range(rect.Min.Y, rect.Max.Y)}
+}
+
+// Rovnoměrně náhodný bod v kruhu (odmocnina zabrání shlukování u středu)
+func (r *Rand) PointInCircle(c Circle) Point {
+	return c.Center.AddVector(r.UnitVector().Scale(c.Radius * math.Sqrt(r.Float64())))
+}
+
+// Náhodný bod na kružnici
+func (r *Rand) PointOnCircle(c Circle) Point {
+	return c.Center.AddVector(r.UnitVector().Scale(c.Radius))
+}
+
+// Rovnoměrně náhodný bod v trojúhelníku
+func (r *Rand) PointInTriangle(a, b, c Point) Point {
+	u, v := r.Float64(), r.Float64()
+	if u+v > 1 {
+		u, v = 1-u, 1-v // překlopení z druhé poloviny rovnoběžníku
+	}
+	return a.AddVector(subPoints(b, a).Scale(u)).AddVector(subPoints(c, a).Scale(v))
+}
+
+// Rovnoměrně náhodný bod v polygonu (i nekonvexním)
+// Trojúhelníky z triangulace se vybírají podle plochy
+func (r *Rand) PointInPolygon(p Polygon) (Point, error) {
+	tris, err := p.Triangulate()
+	if err != nil {
+		return Point{}, err
+	}
+	areas := make([]float64, len(tris))
+	for i, t := range tris {
+		areas[i] = math.Abs(cross(t[0], t[1], t[2]))
+	}
+	i := r.WeightedIndex(areas)
+	if i < 0 {
+		return Point{}, fmt.Errorf("polygon has zero area")
+	}
+	t := tris[i]
+	return r.PointInTriangle(t[0], t[1], t[2]), nil
+}